@@ -0,0 +1,97 @@
+package aggregate
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	tileSize      = 256
+	ClusterCellPx = 64
+)
+
+var DefaultClusterZooms = []int{5, 7, 9, 11}
+
+type Cluster struct {
+	Tile         [2]int         `json:"tile"`
+	Lat          float64        `json:"lat"`
+	Lng          float64        `json:"lng"`
+	Companies    int            `json:"companies"`
+	Postings     int            `json:"postings"`
+	RoleFamilies map[string]int `json:"role_families"`
+	Regions      []string       `json:"regions"`
+}
+
+type clusterCell struct {
+	x, y int
+}
+
+// GridClusters bins companies into square cells of ClusterCellPx screen
+// pixels at the given web-mercator zoom. Each cluster sits at the mean
+// position of its members and carries the tile it falls in.
+func GridClusters(records []CompanyRecord, zoom int) []Cluster {
+	type accumulator struct {
+		cluster Cluster
+		latSum  float64
+		lngSum  float64
+		regions map[string]struct{}
+	}
+	cells := map[clusterCell]*accumulator{}
+	for _, record := range records {
+		if record.Lat == 0 && record.Lng == 0 {
+			continue
+		}
+		px, py := project(record.Lat, record.Lng, zoom)
+		cell := clusterCell{x: int(px) / ClusterCellPx, y: int(py) / ClusterCellPx}
+		acc, ok := cells[cell]
+		if !ok {
+			acc = &accumulator{
+				cluster: Cluster{
+					Tile:         [2]int{int(px) / tileSize, int(py) / tileSize},
+					RoleFamilies: map[string]int{},
+				},
+				regions: map[string]struct{}{},
+			}
+			cells[cell] = acc
+		}
+		acc.latSum += record.Lat
+		acc.lngSum += record.Lng
+		acc.cluster.Companies++
+		for family, count := range record.RoleFamilies {
+			acc.cluster.RoleFamilies[family] += count
+			acc.cluster.Postings += count
+		}
+		if record.Region != "" {
+			acc.regions[record.Region] = struct{}{}
+		}
+	}
+
+	out := make([]Cluster, 0, len(cells))
+	for _, acc := range cells {
+		cluster := acc.cluster
+		n := float64(cluster.Companies)
+		cluster.Lat = roundCoord(acc.latSum / n)
+		cluster.Lng = roundCoord(acc.lngSum / n)
+		cluster.Regions = sortedKeys(acc.regions)
+		out = append(out, cluster)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Companies != out[j].Companies {
+			return out[i].Companies > out[j].Companies
+		}
+		if out[i].Lat != out[j].Lat {
+			return out[i].Lat > out[j].Lat
+		}
+		return out[i].Lng < out[j].Lng
+	})
+	return out
+}
+
+func project(lat, lng float64, zoom int) (float64, float64) {
+	scale := float64(tileSize) * math.Exp2(float64(zoom))
+	lat = math.Max(-85.05112878, math.Min(85.05112878, lat))
+	sin := math.Sin(lat * math.Pi / 180)
+	x := (lng + 180) / 360 * scale
+	y := (0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * scale
+	return x, y
+}
@@ -0,0 +1,27 @@
+package aggregate
+
+import "testing"
+
+func TestGridClustersMergeByZoom(t *testing.T) {
+	records := []CompanyRecord{
+		{Name: "gangnam", Region: "서울", Lat: 37.4979, Lng: 127.0276, RoleFamilies: map[string]int{"app_web": 2}},
+		{Name: "pangyo", Region: "경기", Lat: 37.3947, Lng: 127.1112, RoleFamilies: map[string]int{"data_ai": 1}},
+		{Name: "haeundae", Region: "부산", Lat: 35.1631, Lng: 129.1636, RoleFamilies: map[string]int{"app_web": 1}},
+	}
+
+	low := GridClusters(records, 5)
+	if len(low) != 2 {
+		t.Fatalf("zoom 5 clusters = %d, want 2", len(low))
+	}
+	capital := low[0]
+	if capital.Companies != 2 || capital.Postings != 3 || capital.RoleFamilies["app_web"] != 2 || capital.RoleFamilies["data_ai"] != 1 {
+		t.Fatalf("capital cluster = %+v", capital)
+	}
+	if len(capital.Regions) != 2 || capital.Regions[0] != "경기" {
+		t.Fatalf("capital regions = %v", capital.Regions)
+	}
+
+	if high := GridClusters(records, 11); len(high) != 3 {
+		t.Fatalf("zoom 11 clusters = %d, want 3", len(high))
+	}
+}
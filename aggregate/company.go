@@ -5,25 +5,32 @@ import (
 	"strings"
 	"time"
 
+	"devatlas/jobcode"
 	"devatlas/model"
 )
 
 type CompanyRecord struct {
-	Name     string
-	Region   string
-	Lat      float64
-	Lng      float64
-	URL      string
-	LastSeen time.Time
+	Name         string
+	Region       string
+	Address      string
+	Lat          float64
+	Lng          float64
+	Precision    string
+	Approximate  bool
+	URL          string
+	LastSeen     time.Time
+	RoleFamilies map[string]int
 }
 
 type CompanyAggregator struct {
 	records map[string]*CompanyRecord
+	jobIDs  map[string]struct{}
 }
 
 func NewCompanyAggregator() *CompanyAggregator {
 	return &CompanyAggregator{
 		records: map[string]*CompanyRecord{},
+		jobIDs:  map[string]struct{}{},
 	}
 }
 
@@ -53,19 +60,27 @@ func (a *CompanyAggregator) Add(job model.NormalizedJob) {
 	record, ok := a.records[key]
 	if !ok {
 		coords := regionCentroids[region]
-		if job.Latitude != 0 || job.Longitude != 0 {
+		precision := model.PrecisionSido
+		if hasCoords(job) {
 			coords = latLng{Lat: job.Latitude, Lng: job.Longitude}
+			precision = jobPrecision(job)
 		}
 		a.records[key] = &CompanyRecord{
-			Name:     job.CompanyName,
-			Region:   region,
-			Lat:      coords.Lat,
-			Lng:      coords.Lng,
-			URL:      url,
-			LastSeen: lastSeen,
+			Name:         job.CompanyName,
+			Region:       region,
+			Address:      job.Address,
+			Lat:          coords.Lat,
+			Lng:          coords.Lng,
+			Precision:    precision,
+			Approximate:  precision != model.PrecisionAddress,
+			URL:          url,
+			LastSeen:     lastSeen,
+			RoleFamilies: map[string]int{},
 		}
+		a.countRole(key, job)
 		return
 	}
+	a.countRole(key, job)
 
 	if lastSeen.After(record.LastSeen) {
 		record.LastSeen = lastSeen
@@ -73,10 +88,47 @@ func (a *CompanyAggregator) Add(job model.NormalizedJob) {
 			record.URL = url
 		}
 	}
-	if record.Lat == 0 && record.Lng == 0 && (job.Latitude != 0 || job.Longitude != 0) {
+	if record.Address == "" && job.Address != "" {
+		record.Address = job.Address
+	}
+	if hasCoords(job) && precisionRank[jobPrecision(job)] > precisionRank[record.Precision] {
 		record.Lat = job.Latitude
 		record.Lng = job.Longitude
+		record.Precision = jobPrecision(job)
+		record.Approximate = record.Precision != model.PrecisionAddress
+	}
+}
+
+func (a *CompanyAggregator) countRole(key string, job model.NormalizedJob) {
+	if job.SourceJobID != "" {
+		id := key + "|" + job.SourceJobID
+		if _, seen := a.jobIDs[id]; seen {
+			return
+		}
+		a.jobIDs[id] = struct{}{}
+	}
+	family := job.RoleFamily
+	if family == "" {
+		family = jobcode.FamilyOther
+	}
+	a.records[key].RoleFamilies[family]++
+}
+
+var precisionRank = map[string]int{
+	model.PrecisionSido:    1,
+	model.PrecisionSigungu: 2,
+	model.PrecisionAddress: 3,
+}
+
+func hasCoords(job model.NormalizedJob) bool {
+	return job.Latitude != 0 || job.Longitude != 0
+}
+
+func jobPrecision(job model.NormalizedJob) string {
+	if _, ok := precisionRank[job.GeoPrecision]; ok {
+		return job.GeoPrecision
 	}
+	return model.PrecisionSido
 }
 
 func (a *CompanyAggregator) ActiveCompanies(cutoff time.Time) []CompanyRecord {
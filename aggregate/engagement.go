@@ -0,0 +1,100 @@
+package aggregate
+
+import (
+	"strings"
+	"time"
+
+	"devatlas/model"
+)
+
+type EngagementStats struct {
+	Region          string  `json:"region,omitempty"`
+	RoleFamily      string  `json:"role_family,omitempty"`
+	Postings        int     `json:"postings"`
+	MedianReads     float64 `json:"median_reads_per_posting"`
+	MedianApplies   float64 `json:"median_applies_per_posting"`
+	MedianApplyRate float64 `json:"median_applies_per_read"`
+}
+
+type engagementBucket struct {
+	reads      []float64
+	applies    []float64
+	applyRates []float64
+}
+
+type EngagementAggregator struct {
+	cutoff   time.Time
+	regions  map[string]*engagementBucket
+	families map[string]*engagementBucket
+}
+
+func NewEngagementAggregator(cutoff time.Time) *EngagementAggregator {
+	return &EngagementAggregator{
+		cutoff:   cutoff,
+		regions:  map[string]*engagementBucket{},
+		families: map[string]*engagementBucket{},
+	}
+}
+
+func (a *EngagementAggregator) Add(state model.PostingState) {
+	if a == nil {
+		return
+	}
+	if state.CountsAt.IsZero() || state.CountsAt.Before(a.cutoff) {
+		return
+	}
+	if region := strings.TrimSpace(state.Region); region != "" {
+		addEngagement(a.regions, region, state)
+	}
+	if family := strings.TrimSpace(state.RoleFamily); family != "" {
+		addEngagement(a.families, family, state)
+	}
+}
+
+func addEngagement(buckets map[string]*engagementBucket, key string, state model.PostingState) {
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &engagementBucket{}
+		buckets[key] = bucket
+	}
+	bucket.reads = append(bucket.reads, float64(state.ReadCount))
+	bucket.applies = append(bucket.applies, float64(state.ApplyCount))
+	if state.ReadCount > 0 {
+		bucket.applyRates = append(bucket.applyRates, float64(state.ApplyCount)/float64(state.ReadCount))
+	}
+}
+
+func (a *EngagementAggregator) ByRegion() []EngagementStats {
+	if a == nil {
+		return nil
+	}
+	out := make([]EngagementStats, 0, len(a.regions))
+	for _, key := range sortedKeys(a.regions) {
+		stats := a.regions[key].stats()
+		stats.Region = key
+		out = append(out, stats)
+	}
+	return out
+}
+
+func (a *EngagementAggregator) ByRoleFamily() []EngagementStats {
+	if a == nil {
+		return nil
+	}
+	out := make([]EngagementStats, 0, len(a.families))
+	for _, key := range sortedKeys(a.families) {
+		stats := a.families[key].stats()
+		stats.RoleFamily = key
+		out = append(out, stats)
+	}
+	return out
+}
+
+func (b *engagementBucket) stats() EngagementStats {
+	return EngagementStats{
+		Postings:        len(b.reads),
+		MedianReads:     roundRatio(median(b.reads)),
+		MedianApplies:   roundRatio(median(b.applies)),
+		MedianApplyRate: roundRatio(median(b.applyRates)),
+	}
+}
@@ -0,0 +1,44 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"devatlas/model"
+)
+
+func TestEngagementAggregator(t *testing.T) {
+	cutoff := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	fresh := cutoff.Add(24 * time.Hour)
+	agg := NewEngagementAggregator(cutoff)
+	for _, state := range []model.PostingState{
+		// 서울 has an even bucket, 부산 an odd one.
+		{Region: "서울", RoleFamily: "app_web", ReadCount: 100, ApplyCount: 10, CountsAt: fresh},
+		{Region: "서울", RoleFamily: "app_web", ReadCount: 300, ApplyCount: 3, CountsAt: fresh},
+		{Region: "부산", RoleFamily: "app_web", ReadCount: 10, ApplyCount: 1, CountsAt: fresh},
+		{Region: "부산", ReadCount: 50, ApplyCount: 0, CountsAt: fresh},
+		{Region: "부산", ReadCount: 0, ApplyCount: 0, CountsAt: cutoff},
+		// Counts taken before the cutoff, or never, are left out.
+		{Region: "서울", RoleFamily: "app_web", ReadCount: 9000, ApplyCount: 900, CountsAt: cutoff.Add(-time.Hour)},
+		{Region: "서울", ReadCount: 9000},
+	} {
+		agg.Add(state)
+	}
+
+	regions := agg.ByRegion()
+	if len(regions) != 2 {
+		t.Fatalf("regions = %+v", regions)
+	}
+	busan, seoul := regions[0], regions[1]
+	if want := (EngagementStats{Region: "부산", Postings: 3, MedianReads: 10, MedianApplies: 0, MedianApplyRate: 0.05}); busan != want {
+		t.Fatalf("부산 = %+v, want %+v", busan, want)
+	}
+	if want := (EngagementStats{Region: "서울", Postings: 2, MedianReads: 200, MedianApplies: 6.5, MedianApplyRate: 0.055}); seoul != want {
+		t.Fatalf("서울 = %+v, want %+v", seoul, want)
+	}
+
+	families := agg.ByRoleFamily()
+	if len(families) != 1 || families[0].Postings != 3 || families[0].MedianReads != 100 {
+		t.Fatalf("role families = %+v", families)
+	}
+}
@@ -0,0 +1,26 @@
+package aggregate
+
+import (
+	"fmt"
+
+	"devatlas/model"
+)
+
+func ExampleRegionAggregator_Results() {
+	agg := NewRegionAggregator()
+	companies := []string{"A", "A", "A", "A", "A", "B", "C"}
+	for i, company := range companies {
+		agg.Add(model.NormalizedJob{
+			SourceJobID: fmt.Sprintf("job-%d", i),
+			CompanyName: company,
+			Region:      "대전",
+		})
+	}
+	agg.Add(model.NormalizedJob{SourceJobID: "job-0", CompanyName: "A", Region: "대전"})
+
+	for _, count := range agg.Results() {
+		fmt.Println(count.Region, count.JobCount, count.CompanyCount, count.CompanyHHI, count.Top5Share, count.Companies5Plus)
+	}
+	// Output:
+	// 대전 7 3 0.551 1 1
+}
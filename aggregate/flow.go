@@ -0,0 +1,81 @@
+package aggregate
+
+import (
+	"sort"
+	"strings"
+
+	"devatlas/model"
+)
+
+type FlowCount struct {
+	Date        string `json:"date"`
+	Region      string `json:"region"`
+	New         int    `json:"new"`
+	Updated     int    `json:"updated"`
+	Expired     int    `json:"expired"`
+	Disappeared int    `json:"disappeared"`
+}
+
+type flowKey struct {
+	date   string
+	region string
+}
+
+type FlowAggregator struct {
+	counts map[flowKey]*FlowCount
+}
+
+func NewFlowAggregator() *FlowAggregator {
+	return &FlowAggregator{
+		counts: map[flowKey]*FlowCount{},
+	}
+}
+
+func (a *FlowAggregator) Add(events ...model.FlowEvent) {
+	if a == nil {
+		return
+	}
+	for _, event := range events {
+		region := strings.TrimSpace(event.Region)
+		if region == "" || event.At.IsZero() {
+			continue
+		}
+		key := flowKey{date: event.At.Format("2006-01-02"), region: region}
+		count, ok := a.counts[key]
+		if !ok {
+			count = &FlowCount{Date: key.date, Region: key.region}
+			a.counts[key] = count
+		}
+		switch event.Kind {
+		case model.FlowNew:
+			count.New++
+		case model.FlowUpdated:
+			count.Updated++
+		case model.FlowExpired:
+			count.Expired++
+		case model.FlowDisappeared:
+			count.Disappeared++
+		}
+	}
+}
+
+func (a *FlowAggregator) Results() []FlowCount {
+	if a == nil {
+		return nil
+	}
+	out := make([]FlowCount, 0, len(a.counts))
+	for _, count := range a.counts {
+		out = append(out, *count)
+	}
+	SortFlowCounts(out)
+	return out
+}
+
+func SortFlowCounts(counts []FlowCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Date == counts[j].Date {
+			return counts[i].Region < counts[j].Region
+		}
+		return counts[i].Date < counts[j].Date
+	})
+}
@@ -0,0 +1,150 @@
+package aggregate
+
+import (
+	"sort"
+	"strings"
+
+	"devatlas/jobstate"
+	"devatlas/model"
+)
+
+type LifetimeStats struct {
+	Region           string  `json:"region,omitempty"`
+	RoleFamily       string  `json:"role_family,omitempty"`
+	Postings         int     `json:"postings"`
+	Closed           int     `json:"closed"`
+	Disappeared      int     `json:"disappeared"`
+	MedianDaysOpen   float64 `json:"median_days_open"`
+	ClosedEarlyShare float64 `json:"closed_early_share"`
+	RepostedShare    float64 `json:"reposted_share"`
+}
+
+type lifetimeBucket struct {
+	postings    int
+	reposted    int
+	closed      int
+	disappeared int
+	closedEarly int
+	daysOpen    []float64
+}
+
+type LifetimeAggregator struct {
+	regions  map[string]*lifetimeBucket
+	families map[string]*lifetimeBucket
+}
+
+func NewLifetimeAggregator() *LifetimeAggregator {
+	return &LifetimeAggregator{
+		regions:  map[string]*lifetimeBucket{},
+		families: map[string]*lifetimeBucket{},
+	}
+}
+
+func (a *LifetimeAggregator) Add(state model.PostingState) {
+	if a == nil {
+		return
+	}
+	if region := strings.TrimSpace(state.Region); region != "" {
+		addLifetime(a.regions, region, state)
+	}
+	if family := strings.TrimSpace(state.RoleFamily); family != "" {
+		addLifetime(a.families, family, state)
+	}
+}
+
+func addLifetime(buckets map[string]*lifetimeBucket, key string, state model.PostingState) {
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &lifetimeBucket{}
+		buckets[key] = bucket
+	}
+	bucket.postings++
+	if state.RepostOf != "" {
+		bucket.reposted++
+	}
+	if !state.Closed() {
+		return
+	}
+	// A disappeared posting was only noticed missing; its close date is when
+	// a run found it gone, not when it closed, so it stays out of the
+	// durations and the early-close share.
+	if state.CloseReason == jobstate.ReasonDisappeared {
+		bucket.disappeared++
+		return
+	}
+	bucket.closed++
+	opened := state.PostedAt
+	if opened.IsZero() {
+		opened = state.FirstSeen
+	}
+	if !opened.IsZero() && !state.ClosedAt.Before(opened) {
+		bucket.daysOpen = append(bucket.daysOpen, state.ClosedAt.Sub(opened).Hours()/24)
+	}
+	if !state.ExpiresAt.IsZero() && state.ClosedAt.Before(state.ExpiresAt) {
+		bucket.closedEarly++
+	}
+}
+
+func (a *LifetimeAggregator) ByRegion() []LifetimeStats {
+	if a == nil {
+		return nil
+	}
+	out := make([]LifetimeStats, 0, len(a.regions))
+	for _, key := range sortedKeys(a.regions) {
+		stats := a.regions[key].stats()
+		stats.Region = key
+		out = append(out, stats)
+	}
+	return out
+}
+
+func (a *LifetimeAggregator) ByRoleFamily() []LifetimeStats {
+	if a == nil {
+		return nil
+	}
+	out := make([]LifetimeStats, 0, len(a.families))
+	for _, key := range sortedKeys(a.families) {
+		stats := a.families[key].stats()
+		stats.RoleFamily = key
+		out = append(out, stats)
+	}
+	return out
+}
+
+func (b *lifetimeBucket) stats() LifetimeStats {
+	stats := LifetimeStats{
+		Postings:       b.postings,
+		Closed:         b.closed,
+		Disappeared:    b.disappeared,
+		MedianDaysOpen: roundRatio(median(b.daysOpen)),
+	}
+	if stats.Closed > 0 {
+		stats.ClosedEarlyShare = roundRatio(float64(b.closedEarly) / float64(stats.Closed))
+	}
+	if b.postings > 0 {
+		stats.RepostedShare = roundRatio(float64(b.reposted) / float64(b.postings))
+	}
+	return stats
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"devatlas/jobstate"
+	"devatlas/model"
+)
+
+func TestLifetimeAggregator(t *testing.T) {
+	posted := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return posted.AddDate(0, 0, n) }
+	agg := NewLifetimeAggregator()
+	for _, state := range []model.PostingState{
+		// Closed by the source five days before its deadline.
+		{JobID: "1", Region: "서울", RoleFamily: "app_web", PostedAt: posted, ExpiresAt: days(15), ClosedAt: days(10), CloseReason: jobstate.ReasonClosed},
+		// Ran to its deadline.
+		{JobID: "2", Region: "서울", RoleFamily: "app_web", PostedAt: posted, ExpiresAt: days(20), ClosedAt: days(20), CloseReason: jobstate.ReasonExpired},
+		// Found missing: counted apart, not as an early close.
+		{JobID: "3", Region: "서울", RoleFamily: "data_ai", PostedAt: posted, ExpiresAt: days(30), ClosedAt: days(2), CloseReason: jobstate.ReasonDisappeared},
+		// Still open, and a repost of 1; no posting date, so first seen is used.
+		{JobID: "4", Region: "서울", RoleFamily: "app_web", FirstSeen: days(11), RepostOf: "1"},
+		{JobID: "5", Region: "부산", FirstSeen: posted, ClosedAt: days(3), CloseReason: jobstate.ReasonClosed},
+	} {
+		agg.Add(state)
+	}
+
+	regions := agg.ByRegion()
+	if len(regions) != 2 || regions[0].Region != "부산" {
+		t.Fatalf("regions = %+v", regions)
+	}
+	seoul := regions[1]
+	want := LifetimeStats{
+		Region:           "서울",
+		Postings:         4,
+		Closed:           2,
+		Disappeared:      1,
+		MedianDaysOpen:   15,
+		ClosedEarlyShare: 0.5,
+		RepostedShare:    0.25,
+	}
+	if seoul != want {
+		t.Fatalf("서울 = %+v, want %+v", seoul, want)
+	}
+	if busan := regions[0]; busan.MedianDaysOpen != 3 || busan.ClosedEarlyShare != 0 {
+		t.Fatalf("부산 = %+v, want 3 days open and no deadline", busan)
+	}
+
+	families := agg.ByRoleFamily()
+	if len(families) != 2 || families[0].RoleFamily != "app_web" || families[0].Postings != 3 || families[0].RepostedShare != 0.333 {
+		t.Fatalf("role families = %+v", families)
+	}
+	if data := families[1]; data.Closed != 0 || data.Disappeared != 1 || data.MedianDaysOpen != 0 {
+		t.Fatalf("data_ai = %+v, want only a disappearance", data)
+	}
+}
@@ -11,19 +11,26 @@ type RegionCount struct {
 	Region       string `json:"region"`
 	JobCount     int    `json:"job_count"`
 	CompanyCount int    `json:"company_count"`
+	// The ratios are nil when the stats table has no usable figure for the
+	// region, so a region without stats is not mistaken for one without jobs.
+	JobsPer100k    *float64 `json:"jobs_per_100k,omitempty"`
+	JobsPerICTFirm *float64 `json:"jobs_per_ict_firm,omitempty"`
+	CompanyHHI     float64  `json:"company_hhi"`
+	Top5Share      float64  `json:"top5_company_share"`
+	Companies5Plus int      `json:"companies_5plus"`
 }
 
 type RegionAggregator struct {
 	jobCounts   map[string]int
 	jobIDs      map[string]map[string]struct{}
-	companySets map[string]map[string]struct{}
+	companyJobs map[string]map[string]int
 }
 
 func NewRegionAggregator() *RegionAggregator {
 	return &RegionAggregator{
 		jobCounts:   map[string]int{},
 		jobIDs:      map[string]map[string]struct{}{},
-		companySets: map[string]map[string]struct{}{},
+		companyJobs: map[string]map[string]int{},
 	}
 }
 
@@ -43,28 +50,38 @@ func (a *RegionAggregator) Add(job model.NormalizedJob) {
 			set = map[string]struct{}{}
 			a.jobIDs[region] = set
 		}
+		if _, exists := set[job.SourceJobID]; exists {
+			return
+		}
 		set[job.SourceJobID] = struct{}{}
 	}
 
 	if job.CompanyName == "" {
 		return
 	}
-	set, ok := a.companySets[region]
+	companies, ok := a.companyJobs[region]
 	if !ok {
-		set = map[string]struct{}{}
-		a.companySets[region] = set
+		companies = map[string]int{}
+		a.companyJobs[region] = companies
 	}
-	set[job.CompanyName] = struct{}{}
+	companies[job.CompanyName]++
 }
 
 func (a *RegionAggregator) Results() []RegionCount {
 	if a == nil {
 		return nil
 	}
-	regions := make([]string, 0, len(a.jobCounts))
+	seen := map[string]struct{}{}
+	regions := make([]string, 0, len(a.jobCounts)+len(a.jobIDs))
 	for region := range a.jobCounts {
+		seen[region] = struct{}{}
 		regions = append(regions, region)
 	}
+	for region := range a.jobIDs {
+		if _, ok := seen[region]; !ok {
+			regions = append(regions, region)
+		}
+	}
 	sort.Strings(regions)
 
 	out := make([]RegionCount, 0, len(regions))
@@ -73,11 +90,44 @@ func (a *RegionAggregator) Results() []RegionCount {
 		if set, ok := a.jobIDs[region]; ok {
 			jobCount += len(set)
 		}
-		out = append(out, RegionCount{
+		count := RegionCount{
 			Region:       region,
 			JobCount:     jobCount,
-			CompanyCount: len(a.companySets[region]),
-		})
+			CompanyCount: len(a.companyJobs[region]),
+		}
+		applyConcentration(&count, a.companyJobs[region])
+		out = append(out, count)
 	}
 	return out
 }
+
+func applyConcentration(count *RegionCount, companies map[string]int) {
+	if len(companies) == 0 {
+		return
+	}
+	postings := make([]int, 0, len(companies))
+	total := 0
+	for _, n := range companies {
+		postings = append(postings, n)
+		total += n
+		if n >= 5 {
+			count.Companies5Plus++
+		}
+	}
+	if total == 0 {
+		return
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(postings)))
+
+	var hhi float64
+	for _, n := range postings {
+		share := float64(n) / float64(total)
+		hhi += share * share
+	}
+	top := 0
+	for i := 0; i < len(postings) && i < 5; i++ {
+		top += postings[i]
+	}
+	count.CompanyHHI = roundRatio(hhi)
+	count.Top5Share = roundRatio(float64(top) / float64(total))
+}
@@ -0,0 +1,60 @@
+package aggregate
+
+import (
+	"reflect"
+	"testing"
+
+	"devatlas/model"
+)
+
+func TestRegionAggregatorCounts(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []model.NormalizedJob
+		want []RegionCount
+	}{
+		{
+			// A region is listed even when every posting in it has an ID.
+			name: "region with identified postings only",
+			jobs: []model.NormalizedJob{
+				{SourceJobID: "1", CompanyName: "A", Region: "부산"},
+				{SourceJobID: "2", CompanyName: "B", Region: "부산"},
+			},
+			want: []RegionCount{{Region: "부산", JobCount: 2, CompanyCount: 2, CompanyHHI: 0.5, Top5Share: 1}},
+		},
+		{
+			// A posting seen twice must not count twice towards its company's
+			// share, or the concentration metrics would drift.
+			name: "duplicate ID counted once",
+			jobs: []model.NormalizedJob{
+				{SourceJobID: "1", CompanyName: "A", Region: "대전"},
+				{SourceJobID: "1", CompanyName: "A", Region: "대전"},
+				{SourceJobID: "2", CompanyName: "B", Region: "대전"},
+			},
+			want: []RegionCount{{Region: "대전", JobCount: 2, CompanyCount: 2, CompanyHHI: 0.5, Top5Share: 1}},
+		},
+		{
+			name: "postings without an ID are all counted",
+			jobs: []model.NormalizedJob{
+				{CompanyName: "A", Region: "서울"},
+				{CompanyName: "A", Region: "서울"},
+				{SourceJobID: "1", Region: "서울"},
+			},
+			want: []RegionCount{{Region: "서울", JobCount: 3, CompanyCount: 1, CompanyHHI: 1, Top5Share: 1}},
+		},
+		{
+			name: "no region",
+			jobs: []model.NormalizedJob{{SourceJobID: "1", CompanyName: "A"}},
+			want: []RegionCount{},
+		},
+	}
+	for _, tt := range tests {
+		agg := NewRegionAggregator()
+		for _, job := range tt.jobs {
+			agg.Add(job)
+		}
+		if got := agg.Results(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Results = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
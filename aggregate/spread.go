@@ -0,0 +1,75 @@
+package aggregate
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+
+	"devatlas/model"
+)
+
+const metersPerDegree = 111320.0
+
+var spreadRadiusMeters = map[string]float64{
+	model.PrecisionAddress: 40,
+	model.PrecisionSigungu: 1500,
+	model.PrecisionSido:    6000,
+}
+
+// SpreadOverlapping fans out records that share a coordinate on a sunflower
+// spiral around it. Placement depends only on the shared point and the sorted
+// names, so the same input always produces the same layout.
+func SpreadOverlapping(records []CompanyRecord) []CompanyRecord {
+	groups := map[string][]int{}
+	for i, record := range records {
+		if record.Lat == 0 && record.Lng == 0 {
+			continue
+		}
+		key := coordKey(record.Lat, record.Lng)
+		groups[key] = append(groups[key], i)
+	}
+
+	goldenAngle := math.Pi * (3 - math.Sqrt(5))
+	for key, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return records[members[i]].Name < records[members[j]].Name
+		})
+		radius := spreadRadiusMeters[model.PrecisionSido]
+		for _, idx := range members {
+			if r, ok := spreadRadiusMeters[records[idx].Precision]; ok && r < radius {
+				radius = r
+			}
+		}
+		rotation := float64(hashKey(key)%3600) / 3600 * 2 * math.Pi
+		centerLat := records[members[0]].Lat
+		centerLng := records[members[0]].Lng
+		cosLat := math.Cos(centerLat * math.Pi / 180)
+		n := float64(len(members))
+		for i, idx := range members {
+			distance := radius * math.Sqrt((float64(i)+0.5)/n)
+			angle := rotation + float64(i)*goldenAngle
+			records[idx].Lat = roundCoord(centerLat + distance*math.Sin(angle)/metersPerDegree)
+			records[idx].Lng = roundCoord(centerLng + distance*math.Cos(angle)/(metersPerDegree*cosLat))
+			records[idx].Approximate = true
+		}
+	}
+	return records
+}
+
+func coordKey(lat, lng float64) string {
+	return strconv.FormatFloat(roundCoord(lat), 'f', 6, 64) + "," + strconv.FormatFloat(roundCoord(lng), 'f', 6, 64)
+}
+
+func roundCoord(value float64) float64 {
+	return math.Round(value*1e6) / 1e6
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,56 @@
+package aggregate
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"devatlas/model"
+)
+
+func TestSpreadOverlappingIsDeterministic(t *testing.T) {
+	build := func(names ...string) []CompanyRecord {
+		records := make([]CompanyRecord, 0, len(names)+1)
+		for _, name := range names {
+			records = append(records, CompanyRecord{Name: name, Lat: 37.5172, Lng: 127.0473, Precision: model.PrecisionSigungu})
+		}
+		return append(records, CompanyRecord{Name: "solo", Lat: 35.1796, Lng: 129.0756, Precision: model.PrecisionAddress})
+	}
+
+	first := SpreadOverlapping(build("c", "a", "b"))
+	second := SpreadOverlapping(build("b", "c", "a"))
+	byName := func(records []CompanyRecord) map[string]CompanyRecord {
+		out := map[string]CompanyRecord{}
+		for _, record := range records {
+			out[record.Name] = record
+		}
+		return out
+	}
+	if !reflect.DeepEqual(byName(first), byName(second)) {
+		t.Fatalf("layout depends on input order:\n%+v\n%+v", first, second)
+	}
+
+	seen := map[string]bool{}
+	for _, record := range first[:3] {
+		key := coordKey(record.Lat, record.Lng)
+		if seen[key] {
+			t.Fatalf("%s still overlaps another marker", record.Name)
+		}
+		seen[key] = true
+		if !record.Approximate {
+			t.Fatalf("%s should be flagged approximate", record.Name)
+		}
+		if d := distanceMeters(record.Lat, record.Lng, 37.5172, 127.0473); d > spreadRadiusMeters[model.PrecisionSigungu] {
+			t.Fatalf("%s moved %.0fm, beyond the sigungu radius", record.Name, d)
+		}
+	}
+	if solo := first[3]; solo.Lat != 35.1796 || solo.Approximate {
+		t.Fatalf("single marker should not move: %+v", solo)
+	}
+}
+
+func distanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	dy := (lat1 - lat2) * metersPerDegree
+	dx := (lng1 - lng2) * metersPerDegree * math.Cos(lat2*math.Pi/180)
+	return math.Hypot(dx, dy)
+}
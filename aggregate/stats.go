@@ -0,0 +1,145 @@
+package aggregate
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"devatlas/mapper"
+)
+
+//go:embed regionstats.csv
+var bundledRegionStats []byte
+
+type RegionStat struct {
+	Region            string
+	Population        int64
+	ICTEstablishments int64
+}
+
+type RegionStats map[string]RegionStat
+
+func DefaultRegionStats() (RegionStats, error) {
+	return ParseRegionStats(bytes.NewReader(bundledRegionStats))
+}
+
+func LoadRegionStats(path string) (RegionStats, error) {
+	if strings.TrimSpace(path) == "" {
+		return DefaultRegionStats()
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseRegionStats(file)
+}
+
+func ParseRegionStats(r io.Reader) (RegionStats, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("aggregate: region stats table is empty")
+		}
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	regionCol, ok := columns["region"]
+	if !ok {
+		if regionCol, ok = columns["sido"]; !ok {
+			return nil, errors.New("aggregate: region stats table needs a region column")
+		}
+	}
+	populationCol, hasPopulation := columns["population"]
+	ictCol, hasICT := columns["ict_establishments"]
+	if !hasPopulation && !hasICT {
+		return nil, errors.New("aggregate: region stats table needs population or ict_establishments")
+	}
+
+	stats := RegionStats{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if regionCol >= len(record) {
+			continue
+		}
+		region := mapper.NormalizeRegionName(record[regionCol])
+		if region == "" {
+			continue
+		}
+		stat := stats[region]
+		stat.Region = region
+		if hasPopulation {
+			value, err := parseStatValue(record, populationCol)
+			if err != nil {
+				return nil, fmt.Errorf("aggregate: population for %s: %w", region, err)
+			}
+			stat.Population = value
+		}
+		if hasICT {
+			value, err := parseStatValue(record, ictCol)
+			if err != nil {
+				return nil, fmt.Errorf("aggregate: ict_establishments for %s: %w", region, err)
+			}
+			stat.ICTEstablishments = value
+		}
+		stats[region] = stat
+	}
+	return stats, nil
+}
+
+func parseStatValue(record []string, col int) (int64, error) {
+	if col >= len(record) {
+		return 0, nil
+	}
+	raw := strings.ReplaceAll(strings.TrimSpace(record[col]), ",", "")
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+func ApplyRegionStats(counts []RegionCount, stats RegionStats) []RegionCount {
+	if len(stats) == 0 {
+		return counts
+	}
+	for i := range counts {
+		stat, ok := stats[counts[i].Region]
+		if !ok {
+			continue
+		}
+		if stat.Population > 0 {
+			ratio := roundRatio(float64(counts[i].JobCount) * 100000 / float64(stat.Population))
+			counts[i].JobsPer100k = &ratio
+		}
+		if stat.ICTEstablishments > 0 {
+			ratio := roundRatio(float64(counts[i].JobCount) / float64(stat.ICTEstablishments))
+			counts[i].JobsPerICTFirm = &ratio
+		}
+	}
+	return counts
+}
+
+func roundRatio(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,93 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseRegionStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    RegionStats
+		wantErr bool
+	}{
+		{
+			name: "aliases and thousands separators",
+			csv:  "region,population,ict_establishments\n서울특별시,\"9,386,034\",21000\n강원도,1527807,\n",
+			want: RegionStats{
+				"서울": {Region: "서울", Population: 9386034, ICTEstablishments: 21000},
+				"강원": {Region: "강원", Population: 1527807},
+			},
+		},
+		{
+			name: "sido column and short rows",
+			csv:  "sido,population\n부산광역시\n대구,2374960\n",
+			want: RegionStats{
+				"부산": {Region: "부산"},
+				"대구": {Region: "대구", Population: 2374960},
+			},
+		},
+		{name: "non-numeric population", csv: "region,population\n서울,many\n", wantErr: true},
+		{name: "unterminated quote", csv: "region,population\n\"서울,100\n", wantErr: true},
+		{name: "no stat columns", csv: "region,area\n서울,605\n", wantErr: true},
+		{name: "empty", csv: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRegionStats(strings.NewReader(tt.csv))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: ParseRegionStats = %v, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: ParseRegionStats = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for region, want := range tt.want {
+			if got[region] != want {
+				t.Errorf("%s: %s = %+v, want %+v", tt.name, region, got[region], want)
+			}
+		}
+	}
+}
+
+func TestApplyRegionStats(t *testing.T) {
+	stats := RegionStats{
+		"서울": {Region: "서울", Population: 200000, ICTEstablishments: 40},
+		"부산": {Region: "부산", Population: 100000, ICTEstablishments: 10},
+		"세종": {Region: "세종"},
+	}
+	counts := ApplyRegionStats([]RegionCount{
+		{Region: "서울", JobCount: 10},
+		{Region: "부산", JobCount: 0},
+		{Region: "세종", JobCount: 3},
+		{Region: "제주", JobCount: 1},
+	}, stats)
+
+	if counts[0].JobsPer100k == nil || *counts[0].JobsPer100k != 5 ||
+		counts[0].JobsPerICTFirm == nil || *counts[0].JobsPerICTFirm != 0.25 {
+		t.Fatalf("서울 = %+v, want 5 per 100k and 0.25 per firm", counts[0])
+	}
+	// A region with stats but no jobs keeps its computed zero.
+	payload, err := json.Marshal(counts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(payload); !strings.Contains(got, `"jobs_per_100k":0`) || !strings.Contains(got, `"jobs_per_ict_firm":0`) {
+		t.Fatalf("부산 = %s, want zero ratios", got)
+	}
+	// Zero population or firms, and regions missing from the table, are left
+	// without ratios instead of dividing by zero.
+	for _, count := range counts[2:] {
+		if count.JobsPer100k != nil || count.JobsPerICTFirm != nil {
+			t.Fatalf("%s = %+v, want no ratios", count.Region, count)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/fsutil"
+	"devatlas/mapper"
+	"devatlas/model"
+	"devatlas/output"
+	"devatlas/rawstore"
+	"devatlas/runlog"
+	"devatlas/saramin"
+	"devatlas/timeseries"
+)
+
+// Saramin grants 500 calls a day per key by default.
+const defaultDailyQuota = 500
+
+var (
+	errQuotaReached    = errors.New("daily API quota reached")
+	errDayExceedsQuota = errors.New("day needs more calls than the daily quota")
+)
+
+type backfillState struct {
+	Query     string                 `json:"query"`
+	Completed map[string]backfillDay `json:"completed"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type backfillDay struct {
+	Jobs      int       `json:"jobs"`
+	Pages     int       `json:"pages"`
+	Skipped   bool      `json:"series_skipped,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type backfillResult struct {
+	days      int
+	remaining int
+	jobs      int
+	requests  int
+	stopped   bool
+}
+
+func runBackfillCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	settings := a.config.Settings
+	var (
+		accessKey = fs.String("access-key", "", "Saramin access key (overrides SARAMIN_ACCESS_KEY and the config file)")
+		from      = fs.String("from", "", "First publication day, YYYY-MM-DD (required)")
+		to        = fs.String("to", "", "Last publication day, YYYY-MM-DD (default: yesterday)")
+		overwrite = fs.Bool("overwrite", false, "Replace time-series days that a daily collection already wrote")
+		reset     = fs.Bool("reset", false, "Discard the saved backfill progress and start over")
+	)
+	bindQueryFlags(fs, &settings)
+	fs.IntVar(&settings.DailyQuota, "daily-quota", settings.DailyQuota, "Maximum Saramin API calls per day, counting collect and earlier backfill runs (0 = unlimited)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if key := strings.TrimSpace(*accessKey); key != "" {
+		settings.AccessKey = key
+	}
+	cfg, err := newRunConfig(a.paths, settings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.accessKey == "" {
+		fmt.Fprintln(os.Stderr, "missing access key (set -access-key, SARAMIN_ACCESS_KEY or access_key in the config file)")
+		return 2
+	}
+	applyRetryDefaults(&cfg)
+
+	if strings.TrimSpace(*from) == "" {
+		fmt.Fprintln(os.Stderr, "missing -from date")
+		return 2
+	}
+	start, err := time.ParseInLocation(dateLayout, strings.TrimSpace(*from), time.Local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+		return 2
+	}
+	today := time.Now()
+	end := time.Date(today.Year(), today.Month(), today.Day()-1, 0, 0, 0, 0, time.Local)
+	if strings.TrimSpace(*to) != "" {
+		end, err = time.ParseInLocation(dateLayout, strings.TrimSpace(*to), time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -to: %v\n", err)
+			return 2
+		}
+	}
+	if end.Before(start) {
+		fmt.Fprintln(os.Stderr, "-to is before -from")
+		return 2
+	}
+
+	result, err := runBackfill(context.Background(), cfg, start, end, *overwrite, *reset, time.Now)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("days=%d remaining=%d jobs=%d requests=%d\n", result.days, result.remaining, result.jobs, result.requests)
+	if result.stopped {
+		fmt.Println("daily quota reached; run the same command again tomorrow to resume")
+	}
+	return 0
+}
+
+// runBackfill fetches each publication day between start and end that the
+// state file has not recorded yet. A day is archived, counted into the time
+// series and then marked done, each step replacing earlier output for that
+// day, so an interrupted backfill resumes without duplicates.
+func runBackfill(ctx context.Context, cfg runConfig, start, end time.Time, overwrite, reset bool, now func() time.Time) (backfillResult, error) {
+	query := queryFingerprint(cfg)
+	state, err := loadBackfillState(cfg.paths.backfillState())
+	if err != nil {
+		return backfillResult{}, err
+	}
+	if reset || len(state.Completed) == 0 {
+		state = backfillState{Query: query, Completed: map[string]backfillDay{}}
+	}
+	if state.Query != query {
+		return backfillResult{}, fmt.Errorf("%s was written for a different query (%s); pass -reset to start over",
+			cfg.paths.backfillState(), state.Query)
+	}
+
+	quota, err := loadAPIQuota(cfg.paths.apiQuota())
+	if err != nil {
+		return backfillResult{}, err
+	}
+	save := func(at time.Time) error {
+		if err := quota.save(); err != nil {
+			return err
+		}
+		return saveBackfillState(cfg.paths.backfillState(), &state, at)
+	}
+	series, err := timeseries.Load(cfg.paths.timeseries())
+	if err != nil {
+		return backfillResult{}, err
+	}
+	client := newSaraminClient(cfg)
+	params := searchParams(cfg)
+
+	var result backfillResult
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if _, done := state.Completed[date]; done {
+			continue
+		}
+		if result.stopped {
+			result.remaining++
+			continue
+		}
+
+		fetchedAt := now()
+		quota.rollover(fetchedAt)
+		jobs, pages, err := fetchPublishedDay(ctx, client, params, day, quota, cfg.dailyQuota)
+		result.requests += pages
+		if errors.Is(err, errQuotaReached) {
+			result.stopped = true
+			result.remaining++
+			if err := save(fetchedAt); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if err != nil {
+			if saveErr := save(fetchedAt); saveErr != nil {
+				return result, saveErr
+			}
+			return result, fmt.Errorf("backfill %s: %w", date, err)
+		}
+
+		skipped, err := storeBackfillDay(cfg, series, day, jobs, fetchedAt, overwrite)
+		if err != nil {
+			return result, err
+		}
+		state.Completed[date] = backfillDay{Jobs: len(jobs), Pages: pages, Skipped: skipped, FetchedAt: fetchedAt}
+		if err := save(fetchedAt); err != nil {
+			return result, err
+		}
+		result.days++
+		result.jobs += len(jobs)
+	}
+	if result.days > 0 {
+		// The time series is a public file, so the manifest has to follow it;
+		// the rest of the last bundle is carried over as it was.
+		files, err := carriedManifestFiles(cfg.paths)
+		if err != nil {
+			return result, err
+		}
+		finishedAt := now()
+		files = append(files, filepath.Base(cfg.paths.timeseries()))
+		if err := writeManifest(cfg.paths, "backfill-"+runlog.FormatID(finishedAt), finishedAt, files); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// fetchPublishedDay pages through postings published on day. Once the first
+// page reveals the total, a day that would not fit in the remaining quota is
+// abandoned before spending more calls on it, and a day that would not fit
+// in a whole day's quota is an error: waiting for tomorrow cannot help it.
+func fetchPublishedDay(ctx context.Context, client *saramin.Client, params saramin.JobSearchParams, day time.Time, quota *apiQuota, limit int) ([]saramin.Job, int, error) {
+	if limit > 0 && quota.Requests >= limit {
+		return nil, 0, errQuotaReached
+	}
+	params.PublishedMin = day
+	params.PublishedMax = day.AddDate(0, 0, 1).Add(-time.Second)
+
+	var jobs []saramin.Job
+	pages, total := 0, -1
+	err := client.JobSearchPages(ctx, params, func(resp *saramin.JobSearchResponse) error {
+		pages++
+		quota.Requests++
+		if pages == 1 {
+			if parsed, err := strconv.Atoi(resp.Jobs.Total); err == nil {
+				total = parsed
+				calls := (total + params.Count - 1) / params.Count
+				if limit > 0 && calls > limit {
+					return fmt.Errorf("%w: %d postings take %d calls, over -daily-quota %d; raise the quota or narrow the query with -loc-cd or -job-cd",
+						errDayExceedsQuota, total, calls, limit)
+				}
+				if limit > 0 && calls-1 > limit-quota.Requests {
+					return errQuotaReached
+				}
+			}
+		}
+		jobs = append(jobs, resp.Jobs.Job...)
+		done := len(resp.Jobs.Job) < params.Count || (total >= 0 && pages*params.Count >= total)
+		if !done && limit > 0 && quota.Requests >= limit {
+			return errQuotaReached
+		}
+		return nil
+	})
+	return jobs, pages, err
+}
+
+// storeBackfillDay archives the day's postings and upserts their region
+// counts into the time series, unless a daily collection owns that date.
+func storeBackfillDay(cfg runConfig, series *timeseries.Series, day time.Time, jobs []saramin.Job, fetchedAt time.Time, overwrite bool) (bool, error) {
+	raw := make([]model.RawJob, 0, len(jobs))
+	wide := newWideMode(cfg)
+	regionAgg := aggregate.NewRegionAggregator()
+	for _, job := range jobs {
+		record, err := rawJob(job, fetchedAt)
+		if err != nil {
+			return false, err
+		}
+		raw = append(raw, record)
+		if !wide.keep(job) {
+			continue
+		}
+		regionAgg.Add(mapper.NormalizeSaraminJob(job, fetchedAt))
+	}
+	if err := rawstore.WriteDay(cfg.paths.backfillRaw(), day, raw); err != nil {
+		return false, err
+	}
+
+	date := day.Format(dateLayout)
+	if source, ok := series.SourceOf(date); ok && source != timeseries.SourceBackfill && !overwrite {
+		return true, nil
+	}
+	series.UpsertSourceRegions(date, timeseries.SourceBackfill, regionAgg.Results())
+	if series.Meta.UpdatedAt.Before(fetchedAt) {
+		series.Meta.UpdatedAt = fetchedAt
+	}
+	series.Meta.SchemaVersion = output.SchemaVersion
+	return false, timeseries.Save(cfg.paths.timeseries(), series)
+}
+
+func loadBackfillState(path string) (backfillState, error) {
+	var state backfillState
+	if _, err := readJSONFile(path, &state); err != nil {
+		return backfillState{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if state.Completed == nil {
+		state.Completed = map[string]backfillDay{}
+	}
+	return state, nil
+}
+
+func saveBackfillState(path string, state *backfillState, now time.Time) error {
+	state.UpdatedAt = now
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"devatlas/model"
+	"devatlas/rawstore"
+	"devatlas/saramin"
+	"devatlas/timeseries"
+)
+
+// Each day has one posting more than a page holds, so it takes two calls.
+func newBackfillServer(t *testing.T, calls *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		query := r.URL.Query()
+		published, err := strconv.ParseInt(query.Get("published_min"), 10, 64)
+		if err != nil {
+			t.Errorf("published_min = %q", query.Get("published_min"))
+		}
+		start, _ := strconv.Atoi(query.Get("start"))
+		count, _ := strconv.Atoi(query.Get("count"))
+		total := count + 1
+		day := time.Unix(published, 0).Format("0102")
+
+		var resp saramin.JobSearchResponse
+		resp.Jobs.Start = start
+		resp.Jobs.Total = strconv.Itoa(total)
+		for i := start; i < min(start+count, total); i++ {
+			var job saramin.Job
+			job.ID = fmt.Sprintf("%s-%d", day, i)
+			job.Company.Detail.Name = fmt.Sprintf("c%d", i)
+			job.Position.Location.Name = "서울 &gt; 강남구"
+			resp.Jobs.Job = append(resp.Jobs.Job, job)
+		}
+		resp.Jobs.Count = len(resp.Jobs.Job)
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestBackfillResumesWithoutDuplicates(t *testing.T) {
+	calls := 0
+	server := newBackfillServer(t, &calls)
+	defer server.Close()
+
+	paths := newDataPaths(t.TempDir())
+	settings := defaultSettings()
+	settings.AccessKey = "key"
+	settings.DailyQuota = 3
+	settings.MinInterval = time.Nanosecond
+	cfg, err := newRunConfig(paths, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.apiBaseURL = server.URL
+	applyRetryDefaults(&cfg)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 2)
+	clock := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	now := func() time.Time { return clock }
+
+	first, err := runBackfill(context.Background(), cfg, start, end, false, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.stopped || first.days != 1 || first.remaining != 2 {
+		t.Fatalf("first run = %+v, want one day done and a quota stop", first)
+	}
+
+	// Same day: the quota is spent, so nothing is fetched.
+	calls = 0
+	again, err := runBackfill(context.Background(), cfg, start, end, false, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 || again.days != 0 {
+		t.Fatalf("same-day rerun made %d calls, result %+v", calls, again)
+	}
+
+	clock = clock.AddDate(0, 0, 1)
+	cfg.dailyQuota = 10
+	second, err := runBackfill(context.Background(), cfg, start, end, false, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.stopped || second.days != 2 || second.remaining != 0 {
+		t.Fatalf("second run = %+v", second)
+	}
+
+	ids := map[string]int{}
+	err = rawstore.ReadRange(paths.backfillRaw(), time.Time{}, time.Time{}, func(job model.RawJob) error {
+		ids[job.SourceJobID]++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 3 * (saramin.DefaultPageSize + 1); len(ids) != want {
+		t.Fatalf("archived %d ids, want %d", len(ids), want)
+	}
+	for id, n := range ids {
+		if n != 1 {
+			t.Errorf("job %s archived %d times", id, n)
+		}
+	}
+
+	series, err := timeseries.Load(paths.timeseries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Regions) != 3 {
+		t.Fatalf("series = %+v, want one 서울 point per day", series.Regions)
+	}
+	for _, point := range series.Regions {
+		if point.Region != "서울" || point.JobCount != saramin.DefaultPageSize+1 || point.Source != timeseries.SourceBackfill {
+			t.Errorf("point = %+v", point)
+		}
+	}
+
+	var state backfillState
+	if _, err := readJSONFile(paths.backfillState(), &state); err != nil || len(state.Completed) != 3 {
+		t.Fatalf("state = %+v, err %v", state, err)
+	}
+}
+
+func TestBackfillQuota(t *testing.T) {
+	calls := 0
+	server := newBackfillServer(t, &calls)
+	defer server.Close()
+
+	paths := newDataPaths(t.TempDir())
+	settings := defaultSettings()
+	settings.AccessKey = "key"
+	settings.MinInterval = time.Nanosecond
+	cfg, err := newRunConfig(paths, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.apiBaseURL = server.URL
+	applyRetryDefaults(&cfg)
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	clock := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	now := func() time.Time { return clock }
+
+	// Every day takes two calls, so a quota of one can never fit it.
+	cfg.dailyQuota = 1
+	result, err := runBackfill(context.Background(), cfg, day, day, false, false, now)
+	if !errors.Is(err, errDayExceedsQuota) {
+		t.Fatalf("err = %v, want errDayExceedsQuota", err)
+	}
+	if result.stopped {
+		t.Fatalf("result = %+v, want a hard error rather than a quota stop", result)
+	}
+
+	// Calls made by collect count against the same quota.
+	if err := recordAPICalls(paths.apiQuota(), clock, 2); err != nil {
+		t.Fatal(err)
+	}
+	cfg.dailyQuota = 4
+	result, err = runBackfill(context.Background(), cfg, day, day, false, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.stopped || result.days != 0 {
+		t.Fatalf("result = %+v, want a quota stop after collect's calls", result)
+	}
+	quota, err := loadAPIQuota(paths.apiQuota())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Date != "2026-01-10" || quota.Requests != 4 {
+		t.Fatalf("quota = %+v, want 4 calls on 2026-01-10", quota)
+	}
+}
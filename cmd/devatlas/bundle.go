@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"devatlas/fsutil"
+	"devatlas/output"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// stageOutputs returns paths rooted at an empty staging directory inside the
+// data directory, so the final renames never cross file systems.
+func stageOutputs(paths dataPaths) (dataPaths, error) {
+	staging := newDataPaths(paths.staging())
+	if err := os.RemoveAll(staging.dir); err != nil {
+		return dataPaths{}, err
+	}
+	if err := os.MkdirAll(staging.dir, 0o755); err != nil {
+		return dataPaths{}, err
+	}
+	return staging, nil
+}
+
+// commitOutputs moves every staged file into the data directory and then
+// rewrites the manifest. Each rename is atomic, and the manifest goes last,
+// so a crash leaves either the old or the new version of each file and a
+// manifest that only matches a complete bundle. The manifest lists only the
+// staged files: an output this run did not select is not vouched for.
+func commitOutputs(paths, staging dataPaths, runID string, now time.Time) error {
+	var staged, files []string
+	err := filepath.WalkDir(staging.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		staged = append(staged, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range staged {
+		rel, err := filepath.Rel(staging.dir, path)
+		if err != nil {
+			return err
+		}
+		target := paths.join(rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, target); err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	if err := os.RemoveAll(staging.dir); err != nil {
+		return err
+	}
+	return writeManifest(paths, runID, now, files)
+}
+
+// carriedManifestFiles returns the files of the current manifest that still
+// match it, for a run that rewrites some outputs and leaves the rest of the
+// last bundle as it was.
+func carriedManifestFiles(paths dataPaths) ([]string, error) {
+	var m output.Manifest
+	if _, err := readJSONFile(paths.manifest(), &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", paths.manifest(), err)
+	}
+	var files []string
+	for _, file := range m.Files {
+		payload, err := os.ReadFile(paths.join(filepath.FromSlash(file.Path)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if sum := sha256.Sum256(payload); hex.EncodeToString(sum[:]) == file.SHA256 {
+			files = append(files, file.Path)
+		}
+	}
+	return files, nil
+}
+
+// writeManifest hashes files, slash-separated paths relative to the data
+// directory, into the manifest.
+func writeManifest(paths dataPaths, runID string, now time.Time, files []string) error {
+	out := output.Manifest{
+		SchemaVersion: output.SchemaVersion,
+		RunID:         runID,
+		GeneratedAt:   now,
+		Generator:     "devatlas " + generatorVersion(),
+		Files:         []output.ManifestFile{},
+	}
+	seen := map[string]bool{}
+	for _, rel := range files {
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		payload, err := os.ReadFile(paths.join(filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(payload)
+		out.Files = append(out.Files, output.ManifestFile{
+			Path:    rel,
+			Bytes:   int64(len(payload)),
+			SHA256:  hex.EncodeToString(sum[:]),
+			Records: recordCount(payload),
+		})
+	}
+	sort.Slice(out.Files, func(i, j int) bool { return out.Files[i].Path < out.Files[j].Path })
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(paths.manifest(), append(payload, '\n'), 0o644)
+}
+
+// verifyManifest checks that the public files match the manifest, so a
+// bundle mixed from two runs is never published.
+func verifyManifest(paths dataPaths) (output.Manifest, error) {
+	var m output.Manifest
+	found, err := readJSONFile(paths.manifest(), &m)
+	if err != nil {
+		return output.Manifest{}, fmt.Errorf("%s: %w", paths.manifest(), err)
+	}
+	if !found {
+		return output.Manifest{}, fmt.Errorf("%s is missing; run collect or rebuild first", paths.manifest())
+	}
+	listed := map[string]output.ManifestFile{}
+	for _, file := range m.Files {
+		listed[file.Path] = file
+	}
+	err = walkPublicFiles(paths, func(rel, path string) error {
+		file, ok := listed[rel]
+		if !ok {
+			return fmt.Errorf("%s is not in the manifest of run %s; it is left from an earlier run, so rerun with every output or remove it", rel, m.RunID)
+		}
+		delete(listed, rel)
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(payload)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return fmt.Errorf("%s does not match the manifest of run %s", rel, m.RunID)
+		}
+		return nil
+	})
+	if err != nil {
+		return output.Manifest{}, err
+	}
+	for rel := range listed {
+		return output.Manifest{}, fmt.Errorf("%s is in the manifest but missing", rel)
+	}
+	return m, nil
+}
+
+// walkPublicFiles calls fn with the slash-separated relative path of every
+// public file that exists, the manifest excluded.
+func walkPublicFiles(paths dataPaths, fn func(rel, path string) error) error {
+	for _, name := range paths.publicFiles() {
+		if name == manifestFileName {
+			continue
+		}
+		err := filepath.WalkDir(paths.join(name), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(paths.dir, path)
+			if err != nil {
+				return err
+			}
+			return fn(filepath.ToSlash(rel), path)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordCount returns the length of the main array of an output file.
+func recordCount(payload []byte) int {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return 0
+	}
+	for _, key := range []string{"regions", "companies", "features", "clusters"} {
+		var items []json.RawMessage
+		if err := json.Unmarshal(fields[key], &items); err == nil && items != nil {
+			return len(items)
+		}
+	}
+	return 0
+}
+
+func generatorVersion() string {
+	if version != "dev" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return version + "+" + setting.Value[:12]
+		}
+	}
+	return version
+}
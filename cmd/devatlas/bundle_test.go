@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/output"
+	"devatlas/timeseries"
+)
+
+func TestCommitOutputsWritesVerifiableManifest(t *testing.T) {
+	paths := newDataPaths(t.TempDir())
+	runAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	companies := []aggregate.CompanyRecord{
+		{Name: "a", Lat: 37.5, Lng: 127.0, Region: "서울", LastSeen: runAt},
+		{Name: "b", Lat: 35.1, Lng: 129.0, Region: "부산", LastSeen: runAt},
+	}
+
+	staging, err := stageOutputs(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := []aggregate.RegionCount{{Region: "서울", JobCount: 1}, {Region: "부산", JobCount: 1}}
+	if err := writeRegionCounts(staging.regionCounts(), output.RegionCountsMeta{RunAt: runAt}, stats); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCompanyOutputs(staging, nil, runAt, companies); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.regionCounts()); !os.IsNotExist(err) {
+		t.Fatalf("region counts visible before commit: %v", err)
+	}
+	if err := commitOutputs(paths, staging, "run-1", runAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.staging()); !os.IsNotExist(err) {
+		t.Fatalf("staging directory left behind: %v", err)
+	}
+
+	m, err := verifyManifest(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := map[string]int{}
+	for _, file := range m.Files {
+		records[file.Path] = file.Records
+	}
+	if m.RunID != "run-1" || m.SchemaVersion != output.SchemaVersion || records["region_counts.json"] != 2 ||
+		records["latest_companies.json"] != 2 || records["latest_companies.geojson"] != 2 {
+		t.Fatalf("manifest = %+v", m)
+	}
+	if _, ok := records["clusters/z5.json"]; !ok {
+		t.Fatalf("manifest misses cluster tiles: %v", records)
+	}
+
+	if err := writeRegionCounts(paths.regionCounts(), output.RegionCountsMeta{RunAt: runAt.Add(time.Hour)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyManifest(paths); err == nil || !strings.Contains(err.Error(), "region_counts.json") {
+		t.Fatalf("verify after a stray write = %v, want a region_counts.json mismatch", err)
+	}
+	if _, err := publishOutputs(paths, t.TempDir()); err == nil {
+		t.Fatal("published a bundle that does not match its manifest")
+	}
+}
+
+func TestManifestListsOnlyThisRunsOutputs(t *testing.T) {
+	paths := newDataPaths(t.TempDir())
+	runAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	stats := []aggregate.RegionCount{{Region: "서울", JobCount: 1}}
+	commit := func(runID string, companies bool) {
+		t.Helper()
+		staging, err := stageOutputs(paths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeRegionCounts(staging.regionCounts(), output.RegionCountsMeta{RunAt: runAt}, stats); err != nil {
+			t.Fatal(err)
+		}
+		if companies {
+			records := []aggregate.CompanyRecord{{Name: "a", Lat: 37.5, Lng: 127.0, Region: "서울", LastSeen: runAt}}
+			if err := writeCompanyOutputs(staging, nil, runAt, records); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := commitOutputs(paths, staging, runID, runAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A backfill rewrites the time series and carries the rest of the
+	// bundle over unchanged.
+	commit("run-1", true)
+	series := &timeseries.Series{}
+	series.UpsertSourceRegions("2025-06-01", timeseries.SourceBackfill, stats)
+	if err := timeseries.Save(paths.timeseries(), series); err != nil {
+		t.Fatal(err)
+	}
+	files, err := carriedManifestFiles(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeManifest(paths, "backfill-1", runAt, append(files, "region_timeseries.json")); err != nil {
+		t.Fatal(err)
+	}
+	m, err := verifyManifest(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != len(files)+1 {
+		t.Fatalf("backfill manifest = %+v, want run-1's files and the time series", m.Files)
+	}
+
+	// A run with a subset of outputs does not vouch for the others, so the
+	// companies left from run-1 block publishing instead of shipping with it.
+	commit("run-2", false)
+	if _, err := verifyManifest(paths); err == nil || !strings.Contains(err.Error(), "latest_companies") {
+		t.Fatalf("verify after a subset run = %v, want leftover companies reported", err)
+	}
+	if _, err := publishOutputs(paths, t.TempDir()); err == nil {
+		t.Fatal("published outputs left from an earlier run")
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"devatlas/fsutil"
+	"devatlas/saramin"
+)
+
+var errNothingToResume = errors.New("no interrupted collect run to resume")
+
+// collectCheckpoint records how far each slice of a collect run has paged.
+// Postings already processed are kept in a spool next to it, so a resumed
+// run can rebuild its in-memory aggregates without calling the API again.
+type collectCheckpoint struct {
+	RunID     string                      `json:"run_id"`
+	Query     string                      `json:"query"`
+	Slices    map[string]*sliceCheckpoint `json:"slices"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+
+	path  string
+	spool *os.File
+	now   func() time.Time
+}
+
+// sliceCheckpoint is the progress of one query: the next page offset and
+// every posting ID already handled.
+type sliceCheckpoint struct {
+	Start int      `json:"start"`
+	Done  bool     `json:"done,omitempty"`
+	Seen  []string `json:"seen"`
+
+	seen map[string]struct{}
+}
+
+type spoolLine struct {
+	Slice string      `json:"slice"`
+	Job   saramin.Job `json:"job"`
+}
+
+func checkpointPath(dir, runID string) string {
+	return filepath.Join(dir, fmt.Sprintf("collect-%s.json", runID))
+}
+
+func spoolPath(dir, runID string) string {
+	return filepath.Join(dir, fmt.Sprintf("collect-%s.jsonl", runID))
+}
+
+// openCheckpoint starts a checkpoint for runID, or loads the saved one when
+// resume is set. A saved checkpoint must have been taken with the same query.
+func openCheckpoint(dir, runID, query string, resume bool) (*collectCheckpoint, error) {
+	checkpoint := &collectCheckpoint{
+		RunID:  runID,
+		Query:  query,
+		Slices: map[string]*sliceCheckpoint{},
+		path:   checkpointPath(dir, runID),
+		now:    time.Now,
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		found, err := readJSONFile(checkpoint.path, checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", checkpoint.path, err)
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: no checkpoint for run %s", errNothingToResume, runID)
+		}
+		if checkpoint.Query != query {
+			return nil, fmt.Errorf("run %s was collected with %q; rerun with the same settings or start a new run without -resume", runID, checkpoint.Query)
+		}
+		for _, slice := range checkpoint.Slices {
+			slice.index()
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	spool, err := os.OpenFile(spoolPath(dir, runID), flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.spool = spool
+	if !resume {
+		if err := checkpoint.save(); err != nil {
+			checkpoint.close()
+			return nil, err
+		}
+	}
+	return checkpoint, nil
+}
+
+func sliceKey(params saramin.JobSearchParams) string {
+	return fmt.Sprintf("updated_min=%d;updated_max=%d;sort=%s", params.UpdatedMin.Unix(), params.UpdatedMax.Unix(), params.Sort)
+}
+
+func (c *collectCheckpoint) slice(key string) *sliceCheckpoint {
+	if c == nil {
+		return &sliceCheckpoint{seen: map[string]struct{}{}}
+	}
+	slice, ok := c.Slices[key]
+	if !ok {
+		slice = &sliceCheckpoint{seen: map[string]struct{}{}}
+		c.Slices[key] = slice
+	}
+	return slice
+}
+
+// replay feeds the spooled postings of a slice back through fn. Lines
+// written after the last saved checkpoint are ignored, since their page is
+// fetched again.
+func (c *collectCheckpoint) replay(key string, fn func(saramin.Job) error) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	slice := c.slice(key)
+	if len(slice.seen) == 0 {
+		return 0, nil
+	}
+	file, err := os.Open(c.spool.Name())
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	replayed := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var line spoolLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			// A torn last line from an interrupted write.
+			continue
+		}
+		if line.Slice != key {
+			continue
+		}
+		if _, ok := slice.seen[line.Job.ID]; !ok {
+			continue
+		}
+		if _, ok := replayed[line.Job.ID]; ok {
+			continue
+		}
+		replayed[line.Job.ID] = struct{}{}
+		if err := fn(line.Job); err != nil {
+			return len(replayed), err
+		}
+	}
+	return len(replayed), scanner.Err()
+}
+
+// record spools the postings of a finished page and saves the offset of the
+// next one. The spool is synced first, so the saved checkpoint never refers
+// to postings that are not on disk.
+func (c *collectCheckpoint) record(key string, jobs []saramin.Job, next int) error {
+	if c == nil {
+		return nil
+	}
+	slice := c.slice(key)
+	writer := bufio.NewWriter(c.spool)
+	for _, job := range jobs {
+		payload, err := json.Marshal(spoolLine{Slice: key, Job: job})
+		if err != nil {
+			return err
+		}
+		writer.Write(payload)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := c.spool.Sync(); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.ID != "" {
+			slice.Seen = append(slice.Seen, job.ID)
+		}
+	}
+	slice.Start = next
+	return c.save()
+}
+
+func (c *collectCheckpoint) finish(key string) error {
+	if c == nil {
+		return nil
+	}
+	c.slice(key).Done = true
+	return c.save()
+}
+
+func (c *collectCheckpoint) save() error {
+	c.UpdatedAt = c.now()
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(c.path, append(payload, '\n'), 0o644)
+}
+
+func (c *collectCheckpoint) close() error {
+	if c == nil || c.spool == nil {
+		return nil
+	}
+	err := c.spool.Close()
+	c.spool = nil
+	return err
+}
+
+// mark reports whether id is new to the slice and remembers it.
+func (s *sliceCheckpoint) mark(id string) bool {
+	if id == "" {
+		return true
+	}
+	if _, ok := s.seen[id]; ok {
+		return false
+	}
+	s.seen[id] = struct{}{}
+	return true
+}
+
+func (s *sliceCheckpoint) index() {
+	s.seen = make(map[string]struct{}, len(s.Seen))
+	for _, id := range s.Seen {
+		s.seen[id] = struct{}{}
+	}
+}
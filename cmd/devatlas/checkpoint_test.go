@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"devatlas/model"
+	"devatlas/output"
+	"devatlas/rawstore"
+	"devatlas/runlog"
+	"devatlas/saramin"
+)
+
+func TestCollectResumesFromCheckpoint(t *testing.T) {
+	const total = 2*saramin.DefaultPageSize + 30
+	failAt := 2 * saramin.DefaultPageSize
+	var starts []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		starts = append(starts, start)
+		if start == failAt {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var resp saramin.JobSearchResponse
+		resp.Jobs.Start = start
+		resp.Jobs.Total = strconv.Itoa(total)
+		for i := start; i < min(start+saramin.DefaultPageSize, total); i++ {
+			var job saramin.Job
+			job.ID = strconv.Itoa(i)
+			job.Company.Detail.Name = fmt.Sprintf("c%d", i)
+			job.Position.Location.Name = "서울 &gt; 강남구"
+			resp.Jobs.Job = append(resp.Jobs.Job, job)
+		}
+		resp.Jobs.Count = len(resp.Jobs.Job)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	paths := newDataPaths(t.TempDir())
+	settings := defaultSettings()
+	settings.AccessKey = "key"
+	settings.MinInterval = time.Nanosecond
+	settings.Retry.Attempts = 1
+	settings.Geocode.Chain = []string{"gazetteer"}
+	cfg, err := newRunConfig(paths, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.apiBaseURL = server.URL
+	applyRetryDefaults(&cfg)
+
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	if _, err := runOnce(context.Background(), cfg, now); err == nil {
+		t.Fatal("first run succeeded, want the page error")
+	}
+
+	failAt = -1
+	starts = nil
+	cfg.resume = true
+	result, err := runOnce(context.Background(), cfg, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(starts) != 1 || starts[0] != 2*saramin.DefaultPageSize {
+		t.Fatalf("resumed run fetched starts %v, want only the failed page", starts)
+	}
+	if result.jobs != total || result.resumed != 2*saramin.DefaultPageSize {
+		t.Fatalf("result = %+v", result)
+	}
+
+	var counts output.RegionCounts
+	if _, err := readJSONFile(paths.regionCounts(), &counts); err != nil {
+		t.Fatal(err)
+	}
+	if !counts.Meta.RunAt.Equal(now) || len(counts.Regions) != 1 || counts.Regions[0].JobCount != total {
+		t.Fatalf("region counts = %+v", counts)
+	}
+
+	archived := map[string]int{}
+	err = rawstore.ReadRange(paths.raw(), time.Time{}, time.Time{}, func(job model.RawJob) error {
+		archived[job.SourceJobID]++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != total {
+		t.Fatalf("archived %d postings, want %d", len(archived), total)
+	}
+	for id, n := range archived {
+		if n != 1 {
+			t.Errorf("posting %s archived %d times", id, n)
+		}
+	}
+
+	record, err := runlog.NewRecorder(paths.runs()).Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != runlog.StatusCompleted || !record.RunAt.Equal(now) {
+		t.Fatalf("run record = %+v", record)
+	}
+	if _, err := os.Stat(paths.checkpoints()); !os.IsNotExist(err) {
+		t.Fatalf("checkpoints left after a completed run: %v", err)
+	}
+	if _, err := runOnce(context.Background(), cfg, now); err == nil {
+		t.Fatal("resume after a completed run succeeded")
+	}
+}
@@ -0,0 +1,586 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/enrich"
+	"devatlas/geocode"
+	"devatlas/jobcode"
+	"devatlas/jobstate"
+	"devatlas/mapper"
+	"devatlas/model"
+	"devatlas/output"
+	"devatlas/rawstore"
+	"devatlas/runlog"
+	"devatlas/saramin"
+	"devatlas/timeseries"
+)
+
+const (
+	defaultWindow      = 24 * time.Hour
+	defaultCurrentDays = 21
+	defaultSweepDays   = 7
+	defaultMinInterval = 200 * time.Millisecond
+	defaultRetryBase   = 500 * time.Millisecond
+	defaultRetryMax    = 5 * time.Second
+	defaultRetryMaxTry = 3
+	stateRetention     = 180 * 24 * time.Hour
+	rawSource          = "saramin"
+)
+
+type runConfig struct {
+	paths       dataPaths
+	accessKey   string
+	apiBaseURL  string
+	jobCodes    []string
+	jobMidCodes []string
+	locCodes    []string
+	sr          []string
+	sort        string
+	updatedMin  int64
+	updatedMax  int64
+	resume      bool
+	window      time.Duration
+	minInterval time.Duration
+	dailyQuota  int
+	retry       saramin.RetryConfig
+	currentDays int
+	sweepDays   int
+	regionStats string
+	outputs     outputSet
+	wideFilter  string
+	archiveRaw  bool
+	geocoders   []string
+	minGeoConf  float64
+	geoTTL      time.Duration
+	geoNegTTL   time.Duration
+	geoBackend  string
+	geoBudget   int
+	geoBreaker  int
+	addressFile string
+	addressURL  string
+	kakaoKey    string
+	vworldKey   string
+}
+
+type runResult struct {
+	pages           int
+	jobs            int
+	resumed         int
+	missingRegions  int
+	addressFailures int
+	geocode         geocode.Stats
+	coverage        *jobcode.CoverageReport
+	elapsed         time.Duration
+}
+
+func runCollectCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("collect", flag.ContinueOnError)
+	settings := a.config.Settings
+	var (
+		accessKey  = fs.String("access-key", "", "Saramin access key (overrides SARAMIN_ACCESS_KEY and the config file)")
+		updatedMin = fs.Int64("updated-min", 0, "Updated min (unix seconds)")
+		updatedMax = fs.Int64("updated-max", 0, "Updated max (unix seconds)")
+		resume     = fs.Bool("resume", false, "Continue the last interrupted run from its checkpoint, keeping its window")
+	)
+	bindCollectFlags(fs, &settings)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if key := strings.TrimSpace(*accessKey); key != "" {
+		settings.AccessKey = key
+	}
+
+	cfg, err := newRunConfig(a.paths, settings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.accessKey == "" {
+		fmt.Fprintln(os.Stderr, "missing access key (set -access-key, SARAMIN_ACCESS_KEY or access_key in the config file)")
+		return 2
+	}
+	cfg.updatedMin = *updatedMin
+	cfg.updatedMax = *updatedMax
+	cfg.resume = *resume
+
+	applyRetryDefaults(&cfg)
+
+	result, err := runOnce(context.Background(), cfg, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("pages=%d jobs=%d missing_regions=%d address_failures=%d geocode_lookups=%d geocode_failures=%d geocode_skipped=%d geocode_pending=%d elapsed=%s\n",
+		result.pages, result.jobs, result.missingRegions, result.addressFailures,
+		result.geocode.Lookups, result.geocode.Failures, result.geocode.Skipped, result.geocode.Pending,
+		result.elapsed.Round(time.Millisecond))
+	if result.resumed > 0 {
+		fmt.Printf("resumed_jobs=%d\n", result.resumed)
+	}
+	if result.coverage != nil {
+		fmt.Printf("wide total=%d covered=%d missed=%d coverage=%.3f report=%s\n",
+			result.coverage.Total, result.coverage.Covered, result.coverage.Missed, result.coverage.Share, a.paths.coverageReport())
+	}
+	return 0
+}
+
+// runOnce records the run in the run log and checkpoints it page by page. A
+// failed run keeps its checkpoint so -resume can continue it; a completed run
+// drops every checkpoint.
+func runOnce(ctx context.Context, cfg runConfig, now time.Time) (runResult, error) {
+	recorder := runlog.NewRecorder(cfg.paths.runs())
+	var record *runlog.RunRecord
+	if cfg.resume {
+		latest, err := recorder.Latest()
+		if err != nil {
+			return runResult{}, err
+		}
+		if latest == nil || latest.Status == runlog.StatusCompleted {
+			return runResult{}, errNothingToResume
+		}
+		record = latest
+		now = record.RunAt
+		cfg.updatedMin = record.WindowStart.Unix()
+		cfg.updatedMax = record.WindowEnd.Unix()
+	} else if cfg.updatedMin == 0 && cfg.updatedMax == 0 {
+		due, err := sweepDue(cfg, now)
+		if err != nil {
+			return runResult{}, err
+		}
+		if due {
+			cfg.window = max(cfg.window, cfg.currentPeriod())
+		}
+	}
+	windowStart, windowEnd, err := resolveWindow(cfg, now)
+	if err != nil {
+		return runResult{}, err
+	}
+	if record == nil {
+		record, err = recorder.Start(now, windowStart, windowEnd)
+	} else {
+		err = recorder.Resume(record)
+	}
+	if err != nil {
+		return runResult{}, err
+	}
+
+	result, err := collectRun(ctx, cfg, record.ID, now, windowStart, windowEnd)
+	if err == nil {
+		record.Metrics = map[string]int64{
+			"pages":           int64(result.pages),
+			"jobs":            int64(result.jobs),
+			"resumed_jobs":    int64(result.resumed),
+			"missing_regions": int64(result.missingRegions),
+		}
+		err = os.RemoveAll(cfg.paths.checkpoints())
+	}
+	if finishErr := recorder.Finish(record, err); err == nil {
+		err = finishErr
+	}
+	if err != nil {
+		return runResult{}, err
+	}
+	return result, nil
+}
+
+// sweepDue reports whether this run should query the whole current hiring
+// period. The daily window only returns postings updated that day, so
+// postings that quietly went away are found by these periodic wide runs.
+func sweepDue(cfg runConfig, now time.Time) (bool, error) {
+	if cfg.sweepDays <= 0 {
+		return false, nil
+	}
+	state, err := jobstate.Load(cfg.paths.jobState())
+	if err != nil {
+		return false, err
+	}
+	return state.SweptAt.IsZero() || !now.Before(state.SweptAt.AddDate(0, 0, cfg.sweepDays)), nil
+}
+
+func (cfg runConfig) currentPeriod() time.Duration {
+	return time.Duration(cfg.currentDays) * 24 * time.Hour
+}
+
+func collectRun(ctx context.Context, cfg runConfig, runID string, now, windowStart, windowEnd time.Time) (runResult, error) {
+	started := time.Now()
+	checkpoint, err := openCheckpoint(cfg.paths.checkpoints(), runID, queryFingerprint(cfg), cfg.resume)
+	if err != nil {
+		return runResult{}, err
+	}
+	defer checkpoint.close()
+
+	regionStats, err := aggregate.LoadRegionStats(cfg.regionStats)
+	if err != nil {
+		return runResult{}, err
+	}
+
+	wide := newWideMode(cfg)
+	baseParams := searchParams(cfg)
+	client := newSaraminClient(cfg)
+	observedAt := now
+
+	geo, err := initGeocodeResolver(cfg)
+	if err != nil {
+		return runResult{}, err
+	}
+	defer geo.cache.Close()
+
+	var raw *rawstore.FileStore
+	if cfg.archiveRaw {
+		raw = rawstore.NewFileStore(cfg.paths.raw())
+		defer raw.Close()
+	}
+
+	state, err := jobstate.Load(cfg.paths.jobState())
+	if err != nil {
+		return runResult{}, err
+	}
+
+	regionAgg := aggregate.NewRegionAggregator()
+	companyAgg := aggregate.NewCompanyAggregator()
+	flowAgg := aggregate.NewFlowAggregator()
+	missingRegionIDs := map[string]struct{}{}
+	derivedRegionIDs := map[string]struct{}{}
+	issues := make([]regionIssue, 0)
+	pages, jobs, resumed, missing, err := collectWindow(ctx, client, baseParams, windowStart, windowEnd, regionAgg, companyAgg, flowAgg, state, geo, raw, wide, checkpoint, observedAt, missingRegionIDs, derivedRegionIDs, &issues)
+	if quotaErr := recordAPICalls(cfg.paths.apiQuota(), time.Now(), pages); quotaErr != nil && err == nil {
+		err = quotaErr
+	}
+	if err != nil {
+		return runResult{}, err
+	}
+	if _, err := geo.resolver.ResolvePending(ctx); err != nil {
+		return runResult{}, err
+	}
+	flowAgg.Add(state.Sweep(now, windowStart)...)
+	if windowEnd.Sub(windowStart) >= cfg.currentPeriod() {
+		state.SweptAt = now
+	}
+	state.Prune(now.Add(-stateRetention))
+
+	missingCount := missing
+
+	stats := aggregate.ApplyRegionStats(regionAgg.Results(), regionStats)
+	meta := output.RegionCountsMeta{
+		RunAt:          now,
+		WindowStart:    windowStart,
+		WindowEnd:      windowEnd,
+		MissingRegions: missingCount,
+		DerivedRegions: len(derivedRegionIDs),
+		StatsSource:    statsSource(cfg.regionStats),
+	}
+	staging, err := stageOutputs(cfg.paths)
+	if err != nil {
+		return runResult{}, err
+	}
+	if cfg.outputs.has(outputRegionCounts) {
+		if err := writeRegionCounts(staging.regionCounts(), meta, stats); err != nil {
+			return runResult{}, err
+		}
+	}
+
+	activeCompanies := companyAgg.ActiveCompanies(now.AddDate(0, 0, -cfg.currentDays))
+	if err := writeCompanyOutputs(staging, cfg.outputs, now, activeCompanies); err != nil {
+		return runResult{}, err
+	}
+	if len(issues) > 0 && cfg.outputs.has(outputRegionMissing) {
+		if err := appendRegionIssues(cfg.paths.missingRegions(), issues); err != nil {
+			return runResult{}, err
+		}
+	}
+
+	if cfg.outputs.has(outputTimeseries) {
+		series, err := timeseries.Load(cfg.paths.timeseries())
+		if err != nil {
+			return runResult{}, err
+		}
+		series.Meta.SchemaVersion = output.SchemaVersion
+		series.Meta.UpdatedAt = now
+		series.UpsertRegions(now.Format("2006-01-02"), stats)
+		series.AddFlow(flowAgg.Results())
+		if err := timeseries.Save(staging.timeseries(), series); err != nil {
+			return runResult{}, err
+		}
+	}
+	if err := writeStateOutputs(staging, cfg.outputs, now, now.AddDate(0, 0, -cfg.currentDays), state); err != nil {
+		return runResult{}, err
+	}
+	if err := commitOutputs(cfg.paths, staging, runID, now); err != nil {
+		return runResult{}, err
+	}
+	if report := wide.report(); report != nil {
+		if err := writeCoverageReport(cfg.paths.coverageReport(), coverageMeta{
+			RunAt:       now,
+			WindowStart: windowStart,
+			WindowEnd:   windowEnd,
+			JobMidCodes: cfg.jobMidCodes,
+			JobCodes:    len(cfg.jobCodes),
+			Filter:      cfg.wideFilter,
+		}, *report); err != nil {
+			return runResult{}, err
+		}
+	}
+	if err := jobstate.Save(cfg.paths.jobState(), state); err != nil {
+		return runResult{}, err
+	}
+	if err := raw.Close(); err != nil {
+		return runResult{}, err
+	}
+	if err := geo.cache.Close(); err != nil {
+		return runResult{}, err
+	}
+	if err := enrich.SaveLocations(cfg.paths.companyLocations(), geo.locations); err != nil {
+		return runResult{}, err
+	}
+	if err := checkpoint.close(); err != nil {
+		return runResult{}, err
+	}
+
+	return runResult{
+		pages:           pages,
+		jobs:            jobs,
+		resumed:         resumed,
+		missingRegions:  missingCount,
+		addressFailures: geo.addresses.Failures(),
+		geocode:         geo.resolver.Stats(),
+		coverage:        wide.report(),
+		elapsed:         time.Since(started),
+	}, nil
+}
+
+// searchParams builds the query shared by every window; in wide mode the
+// job-code list only classifies results and is not sent.
+func searchParams(cfg runConfig) saramin.JobSearchParams {
+	params := saramin.JobSearchParams{
+		JobCd:    cfg.jobCodes,
+		JobMidCd: cfg.jobMidCodes,
+		LocCd:    cfg.locCodes,
+		Sr:       cfg.sr,
+		Count:    saramin.DefaultPageSize,
+		Sort:     cfg.sort,
+	}
+	if len(cfg.jobMidCodes) > 0 {
+		params.JobCd = nil
+	}
+	return params
+}
+
+// queryFingerprint identifies the postings a run asks for, so saved progress
+// is only reused by a run with the same query.
+func queryFingerprint(cfg runConfig) string {
+	return fmt.Sprintf("job_cd=%s;job_mid_cd=%s;loc_cd=%s;sr=%s;wide_filter=%s",
+		strings.Join(cfg.jobCodes, ","), strings.Join(cfg.jobMidCodes, ","),
+		strings.Join(cfg.locCodes, ","), strings.Join(cfg.sr, ","), cfg.wideFilter)
+}
+
+func newSaraminClient(cfg runConfig) *saramin.Client {
+	return saramin.NewClient(
+		cfg.accessKey,
+		saramin.WithBaseURL(cfg.apiBaseURL),
+		saramin.WithMinInterval(cfg.minInterval),
+		saramin.WithRetryConfig(cfg.retry),
+	)
+}
+
+func resolveWindow(cfg runConfig, now time.Time) (time.Time, time.Time, error) {
+	window := cfg.window
+	if window <= 0 {
+		window = defaultWindow
+	}
+	var start, end time.Time
+	if cfg.updatedMin > 0 {
+		start = time.Unix(cfg.updatedMin, 0)
+	}
+	if cfg.updatedMax > 0 {
+		end = time.Unix(cfg.updatedMax, 0)
+	}
+	if start.IsZero() && end.IsZero() {
+		end = now
+		start = now.Add(-window)
+	} else if start.IsZero() && !end.IsZero() {
+		start = end.Add(-window)
+	} else if !start.IsZero() && end.IsZero() {
+		end = now
+	}
+	if !start.Before(end) {
+		start = end.Add(-window)
+	}
+	return start, end, nil
+}
+
+// collectWindow pages through one slice of the query. Postings the
+// checkpoint already holds are replayed from its spool, and paging continues
+// at the saved offset; every page is checkpointed once it is processed.
+func collectWindow(
+	ctx context.Context,
+	client *saramin.Client,
+	baseParams saramin.JobSearchParams,
+	windowStart time.Time,
+	windowEnd time.Time,
+	regionAgg *aggregate.RegionAggregator,
+	companyAgg *aggregate.CompanyAggregator,
+	flowAgg *aggregate.FlowAggregator,
+	state *jobstate.Store,
+	geo geocodeResolver,
+	raw *rawstore.FileStore,
+	wide *wideMode,
+	checkpoint *collectCheckpoint,
+	observedAt time.Time,
+	missingIDs map[string]struct{},
+	derivedIDs map[string]struct{},
+	issues *[]regionIssue,
+) (int, int, int, int, error) {
+	if windowStart.IsZero() || windowEnd.IsZero() {
+		return 0, 0, 0, 0, errors.New("invalid window range")
+	}
+	if !windowStart.Before(windowEnd) {
+		return 0, 0, 0, 0, nil
+	}
+
+	params := baseParams
+	params.UpdatedMin = windowStart
+	params.UpdatedMax = windowEnd
+	key := sliceKey(params)
+	slice := checkpoint.slice(key)
+
+	var pages int
+	var jobs int
+	var missing int
+	process := func(job saramin.Job) error {
+		if !wide.keep(job) {
+			return nil
+		}
+		normalized := mapper.NormalizeSaraminJob(job, observedAt)
+		if err := geo.locateJob(ctx, &normalized); err != nil {
+			return err
+		}
+		if geo.assignRegion(&normalized) && job.ID != "" && derivedIDs != nil {
+			derivedIDs[job.ID] = struct{}{}
+		}
+		regionAgg.Add(normalized)
+		if companyAgg != nil {
+			companyAgg.Add(normalized)
+		}
+		flowAgg.Add(state.Observe(normalized)...)
+		if normalized.Region == "" {
+			if job.ID != "" && missingIDs != nil {
+				if _, exists := missingIDs[job.ID]; exists {
+					return nil
+				}
+				missingIDs[job.ID] = struct{}{}
+			}
+			missing++
+			if issues != nil {
+				*issues = append(*issues, regionIssue{
+					JobID:         job.ID,
+					Company:       normalized.CompanyName,
+					Title:         normalized.Title,
+					LocationNames: normalized.LocationNames,
+					LocationCodes: normalized.LocationCodes,
+					ObservedAt:    observedAt,
+				})
+			}
+		}
+		return nil
+	}
+
+	resumed, err := checkpoint.replay(key, process)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if slice.Done {
+		return 0, resumed, resumed, missing, nil
+	}
+
+	params.Start = slice.Start
+	err = client.JobSearchPages(ctx, params, func(resp *saramin.JobSearchResponse) error {
+		pages++
+		fresh := make([]saramin.Job, 0, len(resp.Jobs.Job))
+		for _, job := range resp.Jobs.Job {
+			if !slice.mark(job.ID) {
+				continue
+			}
+			fresh = append(fresh, job)
+			if err := archiveJob(raw, job, observedAt); err != nil {
+				return err
+			}
+			if err := process(job); err != nil {
+				return err
+			}
+		}
+		jobs += len(fresh)
+		if err := raw.Flush(); err != nil {
+			return err
+		}
+		if err := geo.cache.Checkpoint(); err != nil {
+			return err
+		}
+		return checkpoint.record(key, fresh, params.Start+pages*params.Count)
+	})
+	if err != nil {
+		// The pages were still spent against the API quota.
+		return pages, 0, 0, 0, err
+	}
+	if err := checkpoint.finish(key); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return pages, jobs + resumed, resumed, missing, nil
+}
+
+func archiveJob(raw *rawstore.FileStore, job saramin.Job, fetchedAt time.Time) error {
+	if raw == nil {
+		return nil
+	}
+	record, err := rawJob(job, fetchedAt)
+	if err != nil {
+		return err
+	}
+	return raw.Append(record)
+}
+
+func rawJob(job saramin.Job, fetchedAt time.Time) (model.RawJob, error) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return model.RawJob{}, err
+	}
+	return model.RawJob{
+		Source:      rawSource,
+		SourceJobID: job.ID,
+		FetchedAt:   fetchedAt,
+		Payload:     payload,
+	}, nil
+}
+
+func applyRetryDefaults(cfg *runConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.minInterval <= 0 {
+		cfg.minInterval = defaultMinInterval
+	}
+	if cfg.retry.MaxAttempts < 1 {
+		cfg.retry.MaxAttempts = defaultRetryMaxTry
+	}
+	if cfg.retry.BaseDelay <= 0 {
+		cfg.retry.BaseDelay = defaultRetryBase
+	}
+	if cfg.retry.MaxDelay <= 0 {
+		cfg.retry.MaxDelay = defaultRetryMax
+	}
+	cfg.retry.StatusCodes = map[int]struct{}{
+		429: {},
+		500: {},
+		502: {},
+		503: {},
+		504: {},
+	}
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"devatlas/fsutil"
+	"devatlas/jobstate"
+	"devatlas/mapper"
+	"devatlas/model"
+	"devatlas/parquet"
+	"devatlas/rawstore"
+	"devatlas/saramin"
+)
+
+const (
+	exportCSV     = "csv"
+	exportJSONL   = "jsonl"
+	exportParquet = "parquet"
+
+	exportSourceRaw      = "raw"
+	exportSourceBackfill = "backfill"
+	exportSourceState    = "state"
+
+	// exportListSeparator joins multi-valued columns such as job codes.
+	exportListSeparator = ";"
+)
+
+// exportRow is one posting as exported. Raw archives fill the posting
+// fields; the state store fills the lifecycle fields.
+type exportRow struct {
+	JobID        string
+	Source       string
+	Company      string
+	Title        string
+	URL          string
+	CompanyURL   string
+	Region       string
+	RegionSource string
+	RoleFamily   string
+	JobCodes     []string
+	Locations    []string
+	Keywords     []string
+	Active       bool
+	ReadCount    int
+	ApplyCount   int
+	PostedAt     time.Time
+	UpdatedAt    time.Time
+	ExpiresAt    time.Time
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	ClosedAt     time.Time
+	CloseReason  string
+}
+
+type exportColumn struct {
+	name string
+	typ  parquet.Type
+	// private columns identify the original posting and are left out of
+	// public exports (DESIGN.md §6.1).
+	private bool
+	value   func(exportRow) any
+}
+
+var exportColumns = []exportColumn{
+	{name: "job_id", typ: parquet.String, private: true, value: func(r exportRow) any { return text(r.JobID) }},
+	{name: "source", typ: parquet.String, value: func(r exportRow) any { return text(r.Source) }},
+	{name: "company", typ: parquet.String, value: func(r exportRow) any { return text(r.Company) }},
+	{name: "title", typ: parquet.String, private: true, value: func(r exportRow) any { return text(r.Title) }},
+	{name: "url", typ: parquet.String, private: true, value: func(r exportRow) any { return text(r.URL) }},
+	{name: "company_url", typ: parquet.String, private: true, value: func(r exportRow) any { return text(r.CompanyURL) }},
+	{name: "region", typ: parquet.String, value: func(r exportRow) any { return text(r.Region) }},
+	{name: "region_source", typ: parquet.String, value: func(r exportRow) any { return text(r.RegionSource) }},
+	{name: "role_family", typ: parquet.String, value: func(r exportRow) any { return text(r.RoleFamily) }},
+	{name: "job_codes", typ: parquet.String, value: func(r exportRow) any { return text(strings.Join(r.JobCodes, exportListSeparator)) }},
+	{name: "locations", typ: parquet.String, value: func(r exportRow) any { return text(strings.Join(r.Locations, exportListSeparator)) }},
+	{name: "keywords", typ: parquet.String, value: func(r exportRow) any { return text(strings.Join(r.Keywords, exportListSeparator)) }},
+	{name: "active", typ: parquet.Bool, value: func(r exportRow) any { return r.Active }},
+	{name: "read_count", typ: parquet.Int64, value: func(r exportRow) any { return r.ReadCount }},
+	{name: "apply_count", typ: parquet.Int64, value: func(r exportRow) any { return r.ApplyCount }},
+	{name: "posted_at", typ: parquet.Timestamp, value: func(r exportRow) any { return r.PostedAt }},
+	{name: "updated_at", typ: parquet.Timestamp, value: func(r exportRow) any { return r.UpdatedAt }},
+	{name: "expires_at", typ: parquet.Timestamp, value: func(r exportRow) any { return r.ExpiresAt }},
+	{name: "first_seen", typ: parquet.Timestamp, value: func(r exportRow) any { return r.FirstSeen }},
+	{name: "last_seen", typ: parquet.Timestamp, value: func(r exportRow) any { return r.LastSeen }},
+	{name: "closed_at", typ: parquet.Timestamp, value: func(r exportRow) any { return r.ClosedAt }},
+	{name: "close_reason", typ: parquet.String, value: func(r exportRow) any { return text(r.CloseReason) }},
+}
+
+type exportOptions struct {
+	format  string
+	source  string
+	columns []string
+	public  bool
+	from    time.Time
+	to      time.Time
+}
+
+func runExportCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var (
+		format  = fs.String("format", exportCSV, "Output format: csv, jsonl or parquet")
+		source  = fs.String("source", exportSourceRaw, "Postings to export: raw (the collect archive), backfill (the backfill archive) or state (posting state)")
+		from    = fs.String("from", "", "First day to include, YYYY-MM-DD: the archive day, or the last day a tracked posting was seen")
+		to      = fs.String("to", "", "Last day to include, YYYY-MM-DD")
+		public  = fs.Bool("public", false, "Leave out job IDs, titles and URLs so the export can be shared (DESIGN.md §6.1)")
+		out     = fs.String("out", "-", "File to write, or - for standard output")
+		columns []string
+	)
+	fs.Var(csvFlag{&columns}, "columns", "Comma-separated columns to write (default: all): "+strings.Join(exportColumnNames(), ","))
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	opts := exportOptions{
+		format:  strings.ToLower(strings.TrimSpace(*format)),
+		source:  strings.ToLower(strings.TrimSpace(*source)),
+		columns: columns,
+		public:  *public,
+	}
+	for _, bound := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{{"-from", *from, &opts.from}, {"-to", *to, &opts.to}} {
+		if strings.TrimSpace(bound.value) == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation(dateLayout, strings.TrimSpace(bound.value), time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid %s: %v\n", bound.name, err)
+			return 2
+		}
+		*bound.dst = parsed
+	}
+	if _, err := selectExportColumns(opts.columns, opts.public); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var buf bytes.Buffer
+	rows, err := exportJobs(a.paths, opts, &buf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	path := strings.TrimSpace(*out)
+	if path == "" || path == "-" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "rows=%d format=%s\n", rows, opts.format)
+		return 0
+	}
+	if err := fsutil.WriteFileAtomic(path, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("rows=%d format=%s out=%s\n", rows, opts.format, path)
+	return 0
+}
+
+// exportJobs writes the selected postings to w, one row per posting ordered
+// by job ID, and returns the number of rows.
+func exportJobs(paths dataPaths, opts exportOptions, w io.Writer) (int, error) {
+	columns, err := selectExportColumns(opts.columns, opts.public)
+	if err != nil {
+		return 0, err
+	}
+	var rows []exportRow
+	switch opts.source {
+	case exportSourceRaw:
+		rows, err = rawExportRows(paths.raw(), opts.from, opts.to)
+	case exportSourceBackfill:
+		rows, err = rawExportRows(paths.backfillRaw(), opts.from, opts.to)
+	case exportSourceState:
+		rows, err = stateExportRows(paths.jobState(), opts.from, opts.to)
+	default:
+		return 0, fmt.Errorf("unknown export source %q", opts.source)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch opts.format {
+	case exportCSV:
+		err = writeExportCSV(w, columns, rows)
+	case exportJSONL:
+		err = writeExportJSONL(w, columns, rows)
+	case exportParquet:
+		err = writeExportParquet(w, columns, rows)
+	default:
+		return 0, fmt.Errorf("unknown export format %q", opts.format)
+	}
+	return len(rows), err
+}
+
+func selectExportColumns(names []string, public bool) ([]exportColumn, error) {
+	if len(names) == 0 {
+		var out []exportColumn
+		for _, column := range exportColumns {
+			if public && column.private {
+				continue
+			}
+			out = append(out, column)
+		}
+		return out, nil
+	}
+	byName := make(map[string]exportColumn, len(exportColumns))
+	for _, column := range exportColumns {
+		byName[column.name] = column
+	}
+	out := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		column, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown export column %q (available: %s)", name, strings.Join(exportColumnNames(), ","))
+		}
+		if public && column.private {
+			return nil, fmt.Errorf("column %q is not allowed in a public export", column.name)
+		}
+		out = append(out, column)
+	}
+	return out, nil
+}
+
+func exportColumnNames() []string {
+	names := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// rawExportRows normalizes the archived postings between from and to. A
+// posting fetched on several days is exported once, from its latest fetch,
+// with the first and last fetch as first_seen and last_seen.
+func rawExportRows(dir string, from, to time.Time) ([]exportRow, error) {
+	byID := map[string]*exportRow{}
+	err := rawstore.ReadRange(dir, from, to, func(raw model.RawJob) error {
+		if raw.Source != "" && raw.Source != rawSource {
+			return nil
+		}
+		var job saramin.Job
+		if err := json.Unmarshal(raw.Payload, &job); err != nil {
+			return fmt.Errorf("raw job %s: %w", raw.SourceJobID, err)
+		}
+		normalized := mapper.NormalizeSaraminJob(job, raw.FetchedAt)
+		if normalized.SourceJobID == "" {
+			return nil
+		}
+		row := exportRow{
+			JobID:        normalized.SourceJobID,
+			Source:       normalized.Source,
+			Company:      normalized.CompanyName,
+			Title:        normalized.Title,
+			URL:          normalized.SourceURL,
+			CompanyURL:   normalized.CompanyURL,
+			Region:       normalized.Region,
+			RegionSource: normalized.RegionSource,
+			RoleFamily:   normalized.RoleFamily,
+			JobCodes:     normalized.JobCodes,
+			Locations:    normalized.LocationNames,
+			Keywords:     normalized.Keywords,
+			Active:       normalized.Active,
+			ReadCount:    normalized.ReadCount,
+			ApplyCount:   normalized.ApplyCount,
+			PostedAt:     normalized.PostedAt,
+			UpdatedAt:    normalized.UpdatedAt,
+			ExpiresAt:    normalized.ExpiresAt,
+			FirstSeen:    raw.FetchedAt,
+			LastSeen:     raw.FetchedAt,
+		}
+		if seen, ok := byID[row.JobID]; ok {
+			if raw.FetchedAt.Before(seen.LastSeen) {
+				seen.FirstSeen = minTime(seen.FirstSeen, raw.FetchedAt)
+				return nil
+			}
+			row.FirstSeen = minTime(seen.FirstSeen, raw.FetchedAt)
+		}
+		byID[row.JobID] = &row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]exportRow, 0, len(byID))
+	for _, row := range byID {
+		rows = append(rows, *row)
+	}
+	sortExportRows(rows)
+	return rows, nil
+}
+
+// stateExportRows lists the tracked postings seen at some point between from
+// and to.
+func stateExportRows(path string, from, to time.Time) ([]exportRow, error) {
+	state, err := jobstate.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var rows []exportRow
+	for _, posting := range state.Jobs {
+		if !from.IsZero() && posting.LastSeen.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !posting.FirstSeen.Before(to.AddDate(0, 0, 1)) {
+			continue
+		}
+		rows = append(rows, exportRow{
+			JobID:        posting.JobID,
+			Source:       rawSource,
+			Company:      posting.CompanyName,
+			Title:        posting.Title,
+			Region:       posting.Region,
+			RegionSource: posting.RegionSource,
+			RoleFamily:   posting.RoleFamily,
+			Active:       !posting.Closed(),
+			ReadCount:    posting.ReadCount,
+			ApplyCount:   posting.ApplyCount,
+			PostedAt:     posting.PostedAt,
+			UpdatedAt:    posting.UpdatedAt,
+			ExpiresAt:    posting.ExpiresAt,
+			FirstSeen:    posting.FirstSeen,
+			LastSeen:     posting.LastSeen,
+			ClosedAt:     posting.ClosedAt,
+			CloseReason:  posting.CloseReason,
+		})
+	}
+	sortExportRows(rows)
+	return rows, nil
+}
+
+func sortExportRows(rows []exportRow) {
+	sort.Slice(rows, func(i, j int) bool { return rows[i].JobID < rows[j].JobID })
+}
+
+func writeExportCSV(w io.Writer, columns []exportColumn, rows []exportRow) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = formatExportValue(column.value(row))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeExportJSONL writes one object per row with the keys in column order.
+func writeExportJSONL(w io.Writer, columns []exportColumn, rows []exportRow) error {
+	var line bytes.Buffer
+	for _, row := range rows {
+		line.Reset()
+		line.WriteByte('{')
+		for i, column := range columns {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			value := column.value(row)
+			if t, ok := value.(time.Time); ok {
+				value = text(formatExportValue(t))
+			}
+			key, _ := json.Marshal(column.name)
+			payload, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			line.Write(key)
+			line.WriteByte(':')
+			line.Write(payload)
+		}
+		line.WriteString("}\n")
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExportParquet(w io.Writer, columns []exportColumn, rows []exportRow) error {
+	schema := make([]parquet.Column, len(columns))
+	for i, column := range columns {
+		schema[i] = parquet.Column{Name: column.name, Type: column.typ}
+	}
+	writer := parquet.NewWriter(w, schema)
+	writer.CreatedBy = "devatlas " + generatorVersion()
+	values := make([]any, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			values[i] = column.value(row)
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func formatExportValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// text maps an empty string to a missing value.
+func text(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
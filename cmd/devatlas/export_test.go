@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"devatlas/model"
+	"devatlas/rawstore"
+	"devatlas/saramin"
+)
+
+func TestExportJobsFromRawArchive(t *testing.T) {
+	paths := newDataPaths(t.TempDir())
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	rawJob := func(id, title string, fetched time.Time) model.RawJob {
+		var job saramin.Job
+		job.ID = id
+		job.URL = "https://www.saramin.co.kr/" + id
+		job.Company.Detail.Name = "company-" + id
+		job.Position.Title = title
+		job.Position.Location.Name = "서울 &gt; 강남구"
+		payload, err := json.Marshal(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return model.RawJob{Source: rawSource, SourceJobID: id, FetchedAt: fetched, Payload: payload}
+	}
+	if err := rawstore.WriteDay(paths.raw(), day, []model.RawJob{rawJob("1", "old title", day), rawJob("2", "backend", day)}); err != nil {
+		t.Fatal(err)
+	}
+	next := day.AddDate(0, 0, 1)
+	if err := rawstore.WriteDay(paths.raw(), next, []model.RawJob{rawJob("1", "new title", next)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rows, err := exportJobs(paths, exportOptions{format: exportCSV, source: exportSourceRaw, public: true}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 2 || len(records) != 3 {
+		t.Fatalf("rows = %d records = %d, want one row per posting", rows, len(records))
+	}
+	header := strings.Join(records[0], ",")
+	if strings.Contains(header, "job_id") || strings.Contains(header, "title") || strings.Contains(header, "url") {
+		t.Fatalf("public export header = %s, want no job IDs, titles or URLs", header)
+	}
+	column := map[string]int{}
+	for i, name := range records[0] {
+		column[name] = i
+	}
+	first := records[1]
+	if first[column["company"]] != "company-1" || first[column["region"]] != "서울" ||
+		first[column["first_seen"]] != day.Format(time.RFC3339) || first[column["last_seen"]] != next.Format(time.RFC3339) {
+		t.Fatalf("first row = %v", first)
+	}
+
+	buf.Reset()
+	if _, err := exportJobs(paths, exportOptions{format: exportJSONL, source: exportSourceRaw, columns: []string{"job_id", "title"}, to: day}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.SplitN(buf.String(), "\n", 2)[0]; got != `{"job_id":"1","title":"old title"}` {
+		t.Fatalf("first jsonl line = %s", got)
+	}
+
+	if _, err := selectExportColumns([]string{"job_id", "url"}, true); err == nil {
+		t.Fatal("public export accepted the url column")
+	}
+	// A Saramin job ID leads straight back to the original posting.
+	if _, err := selectExportColumns([]string{"job_id"}, true); err == nil {
+		t.Fatal("public export accepted the job_id column")
+	}
+
+	buf.Reset()
+	if _, err := exportJobs(paths, exportOptions{format: exportParquet, source: exportSourceRaw}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if data := buf.Bytes(); !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("parquet export is missing the PAR1 magic")
+	}
+}
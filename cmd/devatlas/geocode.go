@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"devatlas/enrich"
+	"devatlas/geocode"
+	"devatlas/model"
+)
+
+const (
+	defaultGeocoders      = "gazetteer,kakao,vworld,nominatim"
+	defaultGeocodeBudget  = 1000
+	defaultGeocodeBreaker = 5
+	defaultRefreshBudget  = 200
+)
+
+type geocodeResolver struct {
+	resolver  *geocode.Resolver
+	cache     *geocode.Cache
+	gazetteer *geocode.Gazetteer
+	addresses *enrich.Enricher
+	locations *enrich.Locations
+	reverse   *geocode.ReverseGeocoder
+}
+
+type refreshResult struct {
+	candidates int
+	refreshed  int
+	found      int
+	failed     int
+}
+
+func runGeocodeCommand(a app, args []string) int {
+	if len(args) == 0 || args[0] != "refresh" {
+		fmt.Fprintln(os.Stderr, "usage: devatlas geocode refresh [-provider name] [-budget n]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("geocode refresh", flag.ContinueOnError)
+	settings := a.config.Settings
+	var (
+		provider = fs.String("provider", "", "Refresh every entry answered by this provider instead of only expired entries")
+		budget   = fs.Int("budget", defaultRefreshBudget, "Maximum number of queries to re-geocode")
+	)
+	bindGeocodeFlags(fs, &settings.Geocode)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := newRunConfig(a.paths, settings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.geoBudget = 0
+	result, err := refreshGeocodeCache(context.Background(), cfg, strings.ToLower(strings.TrimSpace(*provider)), max(0, *budget))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("candidates=%d refreshed=%d found=%d failed=%d remaining=%d\n",
+		result.candidates, result.refreshed, result.found, result.failed, result.candidates-result.refreshed-result.failed)
+	return 0
+}
+
+func refreshGeocodeCache(ctx context.Context, cfg runConfig, provider string, budget int) (refreshResult, error) {
+	geo, err := initGeocodeResolver(cfg)
+	if err != nil {
+		return refreshResult{}, err
+	}
+	defer geo.cache.Close()
+	if geo.resolver == nil {
+		return refreshResult{}, errors.New("no geocoders available")
+	}
+
+	var candidates []geocode.CacheEntry
+	for _, entry := range geo.cache.List() {
+		if provider != "" {
+			if entry.Provider == provider {
+				candidates = append(candidates, entry)
+			}
+			continue
+		}
+		if geo.resolver.Stale(entry) {
+			candidates = append(candidates, entry)
+		}
+	}
+
+	result := refreshResult{candidates: len(candidates)}
+	for _, entry := range candidates {
+		if result.refreshed+result.failed >= budget {
+			break
+		}
+		refreshed, err := geo.resolver.Refresh(ctx, entry)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return result, ctxErr
+			}
+			if errors.Is(err, geocode.ErrCircuitOpen) {
+				break
+			}
+			result.failed++
+			continue
+		}
+		result.refreshed++
+		if refreshed.Found {
+			result.found++
+		}
+		if err := geo.cache.Checkpoint(); err != nil {
+			return result, err
+		}
+	}
+	return result, geo.cache.Close()
+}
+
+func initGeocodeResolver(cfg runConfig) (geocodeResolver, error) {
+	gazetteer, err := geocode.NewGazetteer()
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	boundaries, err := geocode.NewBoundaries()
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	chain, err := buildGeocoderChain(cfg, gazetteer)
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	cache, err := openGeocodeCache(cfg.paths, cfg.geoBackend)
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	addresses, err := buildAddressSources(cfg)
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	locations, err := enrich.LoadLocations(cfg.paths.companyLocations())
+	if err != nil {
+		return geocodeResolver{}, err
+	}
+	geo := geocodeResolver{
+		cache:     cache,
+		gazetteer: gazetteer,
+		addresses: addresses,
+		locations: locations,
+		reverse:   geocode.NewReverseGeocoder(boundaries, gazetteer),
+	}
+	if chain.Len() > 0 {
+		validator := geocode.NewValidator(boundaries, gazetteer)
+		geo.resolver = geocode.NewResolver(chain, cache,
+			geocode.WithValidator(validator, cfg.minGeoConf),
+			geocode.WithCacheTTL(cfg.geoTTL, cfg.geoNegTTL),
+			geocode.WithBudget(cfg.geoBudget),
+			geocode.WithCircuitBreaker(cfg.geoBreaker, geocode.DefaultBreakerCooldown),
+		)
+	}
+	return geo, nil
+}
+
+func buildAddressSources(cfg runConfig) (*enrich.Enricher, error) {
+	var sources []enrich.AddressSource
+	file, err := enrich.LoadFileSource(cfg.addressFile)
+	if err != nil {
+		return nil, err
+	}
+	if file.Len() > 0 {
+		sources = append(sources, file)
+	}
+	if cfg.addressURL != "" {
+		sources = append(sources, enrich.NewHTTPSource(cfg.addressURL))
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	return enrich.NewEnricher(sources...), nil
+}
+
+func openGeocodeCache(paths dataPaths, backend string) (*geocode.Cache, error) {
+	switch strings.ToLower(backend) {
+	case "", "json":
+		return geocode.OpenCache(paths.geocodeCache())
+	case "journal":
+		return geocode.OpenJournalCache(paths.geocodeJournal())
+	default:
+		return nil, fmt.Errorf("unknown geocode cache backend %q", backend)
+	}
+}
+
+// loadGeocodeCache reads the cache without opening it for writing, for
+// commands that only inspect it.
+func loadGeocodeCache(paths dataPaths, backend string) (*geocode.Cache, error) {
+	switch strings.ToLower(backend) {
+	case "", "json":
+		return geocode.LoadCache(paths.geocodeCache())
+	case "journal":
+		return geocode.LoadJournalCache(paths.geocodeJournal())
+	default:
+		return nil, fmt.Errorf("unknown geocode cache backend %q", backend)
+	}
+}
+
+func buildGeocoderChain(cfg runConfig, gazetteer *geocode.Gazetteer) (*geocode.Chain, error) {
+	geocoders := make([]geocode.Geocoder, 0, len(cfg.geocoders))
+	for _, name := range cfg.geocoders {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case geocode.ProviderGazetteer:
+			geocoders = append(geocoders, gazetteer)
+		case geocode.ProviderKakao:
+			if cfg.kakaoKey != "" {
+				geocoders = append(geocoders, geocode.NewKakao(cfg.kakaoKey))
+			}
+		case geocode.ProviderVWorld:
+			if cfg.vworldKey != "" {
+				geocoders = append(geocoders, geocode.NewVWorld(cfg.vworldKey))
+			}
+		case geocode.ProviderNominatim:
+			geocoders = append(geocoders, geocode.NewNominatim())
+		default:
+			return nil, fmt.Errorf("unknown geocoder %q", name)
+		}
+	}
+	return geocode.NewChain(geocoders...), nil
+}
+
+func (g geocodeResolver) locateJob(ctx context.Context, job *model.NormalizedJob) error {
+	if g.addresses != nil {
+		address, err := g.addresses.Address(ctx, *job)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		job.Address = address
+	}
+
+	if known, ok := g.locations.Get(job.CompanyName, job.Region); ok && (job.Address == "" || job.Address == known.Address) {
+		job.Address = known.Address
+		job.Latitude = known.Lat
+		job.Longitude = known.Lng
+		job.GeoPrecision = model.PrecisionAddress
+		return nil
+	}
+	if job.Address != "" && g.resolver != nil {
+		result, _, err := g.resolver.Resolve(ctx, job.Address, job.Region)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if result.Found {
+			job.Latitude = result.Lat
+			job.Longitude = result.Lng
+			job.GeoPrecision = model.PrecisionAddress
+			g.locations.Set(enrich.Location{
+				Company:   job.CompanyName,
+				Region:    job.Region,
+				Address:   job.Address,
+				Lat:       result.Lat,
+				Lng:       result.Lng,
+				Provider:  result.Provider,
+				UpdatedAt: job.ObservedAt,
+			})
+			return nil
+		}
+	}
+
+	query := buildGeoQuery(job.LocationNames)
+	if query == "" {
+		return nil
+	}
+	lat, lng, ok, err := g.locate(ctx, query, job.Region)
+	if err != nil {
+		return err
+	}
+	if ok {
+		job.Latitude = lat
+		job.Longitude = lng
+		job.GeoPrecision = model.PrecisionSido
+		if place, found := g.gazetteer.Approximate(query); found && place.Level() != geocode.LevelSido {
+			job.GeoPrecision = model.PrecisionSigungu
+		}
+	}
+	return nil
+}
+
+func (g geocodeResolver) assignRegion(job *model.NormalizedJob) bool {
+	if job.Region != "" || (job.Latitude == 0 && job.Longitude == 0) {
+		return false
+	}
+	place, ok := g.reverse.Reverse(job.Latitude, job.Longitude)
+	if !ok {
+		return false
+	}
+	job.Region = place.Sido
+	job.RegionSource = model.RegionSourceReverse
+	return true
+}
+
+func (g geocodeResolver) locate(ctx context.Context, query, region string) (float64, float64, bool, error) {
+	if g.resolver != nil {
+		result, _, err := g.resolver.Resolve(ctx, query, region)
+		if err != nil && ctx.Err() != nil {
+			return 0, 0, false, ctx.Err()
+		}
+		if result.Found {
+			return result.Lat, result.Lng, true, nil
+		}
+	}
+	if place, ok := g.gazetteer.Approximate(query); ok && (region == "" || place.Sido == region) {
+		return place.Lat, place.Lng, true, nil
+	}
+	return 0, 0, false, nil
+}
+
+func buildGeoQuery(locationNames []string) string {
+	for _, name := range locationNames {
+		candidate := strings.TrimSpace(name)
+		if candidate == "" {
+			continue
+		}
+		if containsRemoteKeyword(candidate) {
+			continue
+		}
+		candidate = strings.NewReplacer(">", " ", "/", " ", ",", " ").Replace(candidate)
+		candidate = strings.Join(strings.Fields(candidate), " ")
+		if candidate == "" {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+func containsRemoteKeyword(value string) bool {
+	lower := strings.ToLower(value)
+	return strings.Contains(lower, "전국") ||
+		strings.Contains(lower, "재택") ||
+		strings.Contains(lower, "원격") ||
+		strings.Contains(lower, "리모트") ||
+		strings.Contains(lower, "remote") ||
+		strings.Contains(lower, "해외")
+}
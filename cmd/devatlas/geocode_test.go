@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devatlas/enrich"
+	"devatlas/geocode"
+	"devatlas/model"
+)
+
+func TestLocateJobKeepsCompanyLocationsPerRegion(t *testing.T) {
+	gazetteer, err := geocode.NewGazetteer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "company_locations.json")
+	// The first entry predates per-region locations and has no region.
+	legacy := `{"companies":{"acme":{"company":"Acme","address":"서울 강남구 테헤란로 1","lat":37.5,"lng":127.03,"updated_at":"2026-01-01T00:00:00Z"}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	locations, err := enrich.LoadLocations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := locations.Get("Acme", "서울"); ok {
+		t.Fatal("a location without a region was kept")
+	}
+	locations.Set(enrich.Location{Company: "Acme", Region: "서울", Address: "서울 강남구 테헤란로 1", Lat: 37.5006, Lng: 127.0365, UpdatedAt: time.Now()})
+	if err := enrich.SaveLocations(path, locations); err != nil {
+		t.Fatal(err)
+	}
+	if locations, err = enrich.LoadLocations(path); err != nil {
+		t.Fatal(err)
+	}
+	geo := geocodeResolver{gazetteer: gazetteer, locations: locations}
+
+	seoul := model.NormalizedJob{CompanyName: "Acme", Region: "서울", LocationNames: []string{"서울 > 강남구"}}
+	if err := geo.locateJob(context.Background(), &seoul); err != nil {
+		t.Fatal(err)
+	}
+	if seoul.GeoPrecision != model.PrecisionAddress || seoul.Latitude != 37.5006 {
+		t.Fatalf("서울 posting = %+v, want the saved office", seoul)
+	}
+
+	// The same company hiring in 부산 is not placed at its 서울 office.
+	busan := model.NormalizedJob{CompanyName: "Acme", Region: "부산", LocationNames: []string{"부산 > 해운대구"}}
+	if err := geo.locateJob(context.Background(), &busan); err != nil {
+		t.Fatal(err)
+	}
+	place, _ := gazetteer.Approximate("부산 해운대구")
+	if busan.GeoPrecision != model.PrecisionSigungu || busan.Address != "" || busan.Latitude != place.Lat || busan.Longitude != place.Lng {
+		t.Fatalf("부산 posting = %+v, want the 해운대구 centroid", busan)
+	}
+}
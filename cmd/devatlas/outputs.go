@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/fsutil"
+	"devatlas/jobstate"
+	"devatlas/output"
+)
+
+type regionIssue struct {
+	JobID         string    `json:"job_id,omitempty"`
+	Company       string    `json:"company,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	LocationNames []string  `json:"location_names,omitempty"`
+	LocationCodes []string  `json:"location_codes,omitempty"`
+	ObservedAt    time.Time `json:"observed_at"`
+}
+
+const (
+	outputRegionCounts     = "region_counts"
+	outputRegionMissing    = "region_missing"
+	outputCompanies        = "latest_companies"
+	outputCompaniesGeoJSON = "companies_geojson"
+	outputClusters         = "clusters"
+	outputTimeseries       = "timeseries"
+	outputLifetimes        = "posting_lifetimes"
+	outputEngagement       = "engagement"
+)
+
+var outputNames = []string{
+	outputRegionCounts,
+	outputRegionMissing,
+	outputCompanies,
+	outputCompaniesGeoJSON,
+	outputClusters,
+	outputTimeseries,
+	outputLifetimes,
+	outputEngagement,
+}
+
+// outputSet selects which outputs a run writes; nil selects all of them.
+type outputSet map[string]bool
+
+func newOutputSet(names []string) (outputSet, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := map[string]bool{}
+	for _, name := range outputNames {
+		known[name] = true
+	}
+	set := outputSet{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !known[name] {
+			return nil, fmt.Errorf("unknown output %q (want %s)", name, strings.Join(outputNames, ", "))
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+func (s outputSet) has(name string) bool {
+	return s == nil || s[name]
+}
+
+// writeCompanyOutputs writes the company list, its GeoJSON twin and the
+// cluster tiles for the given active companies.
+func writeCompanyOutputs(paths dataPaths, outputs outputSet, runAt time.Time, companies []aggregate.CompanyRecord) error {
+	companies = aggregate.SpreadOverlapping(companies)
+	meta := output.LatestCompaniesMeta{
+		RunAt:       runAt,
+		RegionLevel: "sido",
+	}
+	if outputs.has(outputCompanies) {
+		if err := writeLatestCompanies(paths.latestCompanies(), meta, companies); err != nil {
+			return err
+		}
+	}
+	if outputs.has(outputCompaniesGeoJSON) {
+		if err := writeCompaniesGeoJSON(paths.companiesGeoJSON(), meta, companies); err != nil {
+			return err
+		}
+	}
+	if outputs.has(outputClusters) {
+		return writeClusterTiles(paths.clusters(), runAt, companies)
+	}
+	return nil
+}
+
+// writeStateOutputs derives posting lifetimes and engagement from the
+// posting state.
+func writeStateOutputs(paths dataPaths, outputs outputSet, runAt, currentCutoff time.Time, state *jobstate.Store) error {
+	if outputs.has(outputLifetimes) {
+		lifetimeAgg := aggregate.NewLifetimeAggregator()
+		for _, posting := range state.Jobs {
+			lifetimeAgg.Add(posting)
+		}
+		if err := writePostingLifetimes(paths.postingLifetimes(), output.PostingLifetimesMeta{
+			RunAt:    runAt,
+			Postings: len(state.Jobs),
+		}, lifetimeAgg); err != nil {
+			return err
+		}
+	}
+	if outputs.has(outputEngagement) {
+		engagementAgg := aggregate.NewEngagementAggregator(currentCutoff)
+		for _, posting := range state.Jobs {
+			engagementAgg.Add(posting)
+		}
+		return writeEngagement(paths.engagement(), output.EngagementMeta{
+			RunAt:  runAt,
+			Cutoff: currentCutoff,
+		}, engagementAgg)
+	}
+	return nil
+}
+
+func writeRegionCounts(path string, meta output.RegionCountsMeta, stats []aggregate.RegionCount) error {
+	meta.SchemaVersion = output.SchemaVersion
+	payload, err := json.Marshal(output.RegionCounts{
+		Meta:    meta,
+		Regions: stats,
+	})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func appendRegionIssues(path string, issues []regionIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, issue := range issues {
+		payload, err := json.Marshal(issue)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(payload, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLatestCompanies(path string, meta output.LatestCompaniesMeta, companies []aggregate.CompanyRecord) error {
+	meta.SchemaVersion = output.SchemaVersion
+	out := output.LatestCompanies{
+		Meta:      meta,
+		Companies: make([]output.Company, 0, len(companies)),
+	}
+	for _, company := range companies {
+		out.Companies = append(out.Companies, toLatestCompany(company))
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func toLatestCompany(company aggregate.CompanyRecord) output.Company {
+	return output.Company{
+		Name:         company.Name,
+		Lat:          company.Lat,
+		Lng:          company.Lng,
+		Region:       company.Region,
+		Address:      company.Address,
+		Precision:    company.Precision,
+		Approximate:  company.Approximate,
+		URL:          company.URL,
+		AsOf:         company.LastSeen.Format("2006-01-02"),
+		RoleFamilies: company.RoleFamilies,
+	}
+}
+
+func writeCompaniesGeoJSON(path string, meta output.LatestCompaniesMeta, companies []aggregate.CompanyRecord) error {
+	meta.SchemaVersion = output.SchemaVersion
+	out := output.CompaniesGeoJSON{
+		Type:     "FeatureCollection",
+		Meta:     meta,
+		Features: make([]output.Feature, 0, len(companies)),
+	}
+	for _, company := range companies {
+		if company.Lat == 0 && company.Lng == 0 {
+			continue
+		}
+		out.Features = append(out.Features, output.Feature{
+			Type:       "Feature",
+			Geometry:   output.Point{Type: "Point", Coordinates: [2]float64{company.Lng, company.Lat}},
+			Properties: toLatestCompany(company),
+		})
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func writeClusterTiles(dir string, runAt time.Time, companies []aggregate.CompanyRecord) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, zoom := range aggregate.DefaultClusterZooms {
+		clusters := aggregate.GridClusters(companies, zoom)
+		total := 0
+		for _, cluster := range clusters {
+			total += cluster.Companies
+		}
+		payload, err := json.Marshal(output.ClusterTile{
+			Meta: output.ClusterTileMeta{
+				SchemaVersion: output.SchemaVersion,
+				RunAt:         runAt,
+				Zoom:          zoom,
+				CellPx:        aggregate.ClusterCellPx,
+				Companies:     total,
+			},
+			Clusters: clusters,
+		})
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("z%d.json", zoom))
+		if err := fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePostingLifetimes(path string, meta output.PostingLifetimesMeta, agg *aggregate.LifetimeAggregator) error {
+	meta.SchemaVersion = output.SchemaVersion
+	payload, err := json.Marshal(output.PostingLifetimes{
+		Meta:         meta,
+		Regions:      agg.ByRegion(),
+		RoleFamilies: agg.ByRoleFamily(),
+	})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func writeEngagement(path string, meta output.EngagementMeta, agg *aggregate.EngagementAggregator) error {
+	meta.SchemaVersion = output.SchemaVersion
+	regions := agg.ByRegion()
+	for _, region := range regions {
+		meta.Postings += region.Postings
+	}
+	payload, err := json.Marshal(output.Engagement{
+		Meta:         meta,
+		Regions:      regions,
+		RoleFamilies: agg.ByRoleFamily(),
+	})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func statsSource(path string) string {
+	if path == "" {
+		return "bundled"
+	}
+	return filepath.Base(path)
+}
+
+// readJSONFile decodes path into v and reports false when the file does not
+// exist.
+func readJSONFile(path string, v any) (bool, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import "path/filepath"
+
+const (
+	defaultDataDir   = "data"
+	manifestFileName = "manifest.json"
+)
+
+type dataPaths struct {
+	dir string
+}
+
+func newDataPaths(dir string) dataPaths {
+	if dir == "" {
+		dir = defaultDataDir
+	}
+	return dataPaths{dir: dir}
+}
+
+func (p dataPaths) join(name string) string {
+	return filepath.Join(p.dir, name)
+}
+
+func (p dataPaths) regionCounts() string     { return p.join("region_counts.json") }
+func (p dataPaths) latestCompanies() string  { return p.join("latest_companies.json") }
+func (p dataPaths) companiesGeoJSON() string { return p.join("latest_companies.geojson") }
+func (p dataPaths) clusters() string         { return p.join("clusters") }
+func (p dataPaths) missingRegions() string   { return p.join("region_missing.jsonl") }
+func (p dataPaths) postingLifetimes() string { return p.join("posting_lifetimes.json") }
+func (p dataPaths) engagement() string       { return p.join("engagement.json") }
+func (p dataPaths) timeseries() string       { return p.join("region_timeseries.json") }
+func (p dataPaths) jobState() string         { return p.join("job_state.json") }
+func (p dataPaths) geocodeCache() string     { return p.join("geocode_cache.json") }
+func (p dataPaths) geocodeJournal() string   { return p.join("geocode_cache.jsonl") }
+func (p dataPaths) companyLocations() string { return p.join("company_locations.json") }
+func (p dataPaths) addressFile() string      { return p.join("company_addresses.csv") }
+func (p dataPaths) raw() string              { return p.join("raw") }
+func (p dataPaths) coverageReport() string   { return p.join("job_code_coverage.json") }
+func (p dataPaths) backfillRaw() string      { return p.join("backfill") }
+func (p dataPaths) backfillState() string    { return p.join("backfill_state.json") }
+func (p dataPaths) apiQuota() string         { return p.join("api_quota.json") }
+func (p dataPaths) runs() string             { return p.join("runs") }
+func (p dataPaths) checkpoints() string      { return p.join("checkpoints") }
+func (p dataPaths) staging() string          { return p.join(".staging") }
+func (p dataPaths) manifest() string         { return p.join(manifestFileName) }
+
+// publicFiles lists the outputs the static site reads. State, caches, raw
+// archives and issue logs stay in the data directory.
+func (p dataPaths) publicFiles() []string {
+	return []string{
+		"region_counts.json",
+		"latest_companies.json",
+		"latest_companies.geojson",
+		"region_timeseries.json",
+		"posting_lifetimes.json",
+		"engagement.json",
+		"clusters",
+		manifestFileName,
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"devatlas/fsutil"
+)
+
+const defaultPublishDir = "public/data"
+
+func runPublishCommand(a app, args []string) int {
+	flags := flag.NewFlagSet("publish", flag.ContinueOnError)
+	out := flags.String("out", defaultPublishDir, "Directory to copy the public outputs into")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	dir := strings.TrimSpace(*out)
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "missing -out directory")
+		return 2
+	}
+	copied, err := publishOutputs(a.paths, dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("files=%d out=%s\n", copied, dir)
+	return 0
+}
+
+// publishOutputs copies the files the static site reads into dir, after
+// checking them against the manifest. Posting state, caches, raw archives and
+// issue logs are never published.
+func publishOutputs(paths dataPaths, dir string) (int, error) {
+	if _, err := os.Stat(paths.regionCounts()); err != nil {
+		return 0, fmt.Errorf("nothing to publish: %w", err)
+	}
+	if _, err := verifyManifest(paths); err != nil {
+		return 0, fmt.Errorf("not publishing an inconsistent bundle: %w", err)
+	}
+	copied := 0
+	for _, name := range paths.publicFiles() {
+		src := paths.join(name)
+		err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(paths.dir, path)
+			if err != nil {
+				return err
+			}
+			payload, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := fsutil.WriteFileAtomic(filepath.Join(dir, rel), payload, 0o644); err != nil {
+				return err
+			}
+			copied++
+			return nil
+		})
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return copied, err
+		}
+	}
+	return copied, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devatlas/fsutil"
+)
+
+// apiQuota counts Saramin API calls per local calendar day. collect and
+// backfill share one key, so both record their calls in the same file and
+// backfill spends only what is left.
+type apiQuota struct {
+	Date     string `json:"date"`
+	Requests int    `json:"requests"`
+
+	path string
+}
+
+func loadAPIQuota(path string) (*apiQuota, error) {
+	quota := &apiQuota{path: path}
+	if _, err := readJSONFile(path, quota); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return quota, nil
+}
+
+func (q *apiQuota) rollover(now time.Time) {
+	if date := now.Format(dateLayout); q.Date != date {
+		q.Date = date
+		q.Requests = 0
+	}
+}
+
+// record adds calls made at now and saves the count.
+func (q *apiQuota) record(now time.Time, calls int) error {
+	q.rollover(now)
+	q.Requests += calls
+	return q.save()
+}
+
+func (q *apiQuota) save() error {
+	payload, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(q.path, append(payload, '\n'), 0o644)
+}
+
+// recordAPICalls adds calls made by a collect run to the shared count.
+func recordAPICalls(path string, now time.Time, calls int) error {
+	if calls == 0 {
+		return nil
+	}
+	quota, err := loadAPIQuota(path)
+	if err != nil {
+		return err
+	}
+	return quota.record(now, calls)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/enrich"
+	"devatlas/geocode"
+	"devatlas/jobstate"
+	"devatlas/mapper"
+	"devatlas/model"
+	"devatlas/output"
+	"devatlas/rawstore"
+	"devatlas/runlog"
+	"devatlas/saramin"
+)
+
+const dateLayout = "2006-01-02"
+
+type rebuildResult struct {
+	jobs           int
+	missingRegions int
+	derived        int
+	runAt          time.Time
+}
+
+func runRebuildCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	settings := a.config.Settings
+	// Rebuilds run offline by default: cached answers plus the gazetteer.
+	settings.Geocode.Chain = []string{geocode.ProviderGazetteer}
+	var (
+		from = fs.String("from", "", "First archive day to read, YYYY-MM-DD (default: current-days before -to)")
+		to   = fs.String("to", "", "Last archive day to read, YYYY-MM-DD (default: today)")
+	)
+	fs.IntVar(&settings.CurrentDays, "current-days", settings.CurrentDays, "Current hiring window in days")
+	fs.StringVar(&settings.RegionStats, "region-stats", settings.RegionStats, "Region statistics CSV for normalization (default: bundled table)")
+	fs.Var(csvFlag{&settings.Outputs}, "outputs", "Comma-separated outputs to write (default: all)")
+	fs.StringVar(&settings.Geocode.AddressFile, "address-file", settings.Geocode.AddressFile, "CSV of street addresses by job_id or company (default <data-dir>/company_addresses.csv)")
+	bindGeocodeFlags(fs, &settings.Geocode)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	settings.Geocode.AddressURL = ""
+	cfg, err := newRunConfig(a.paths, settings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	end := time.Now()
+	if strings.TrimSpace(*to) != "" {
+		parsed, err := time.ParseInLocation(dateLayout, strings.TrimSpace(*to), time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -to: %v\n", err)
+			return 2
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -cfg.currentDays)
+	if strings.TrimSpace(*from) != "" {
+		parsed, err := time.ParseInLocation(dateLayout, strings.TrimSpace(*from), time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+			return 2
+		}
+		start = parsed
+	}
+
+	result, err := rebuildOutputs(context.Background(), cfg, start, end)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("jobs=%d missing_regions=%d derived_regions=%d run_at=%s\n",
+		result.jobs, result.missingRegions, result.derived, result.runAt.Format(time.RFC3339))
+	return 0
+}
+
+// rebuildOutputs regenerates the snapshot outputs from the raw archive
+// between start and end. Posting state and the time series are read but
+// never rewritten, so a rebuild can be repeated safely.
+func rebuildOutputs(ctx context.Context, cfg runConfig, start, end time.Time) (rebuildResult, error) {
+	regionStats, err := aggregate.LoadRegionStats(cfg.regionStats)
+	if err != nil {
+		return rebuildResult{}, err
+	}
+	geo, err := initGeocodeResolver(cfg)
+	if err != nil {
+		return rebuildResult{}, err
+	}
+	defer geo.cache.Close()
+
+	regionAgg := aggregate.NewRegionAggregator()
+	companyAgg := aggregate.NewCompanyAggregator()
+	missingIDs := map[string]struct{}{}
+	derivedIDs := map[string]struct{}{}
+	wide := newWideMode(cfg)
+	var result rebuildResult
+	var windowStart time.Time
+	err = rawstore.ReadRange(cfg.paths.raw(), start, end, func(raw model.RawJob) error {
+		if raw.Source != "" && raw.Source != rawSource {
+			return nil
+		}
+		var job saramin.Job
+		if err := json.Unmarshal(raw.Payload, &job); err != nil {
+			return fmt.Errorf("raw job %s: %w", raw.SourceJobID, err)
+		}
+		if !wide.keep(job) {
+			return nil
+		}
+		normalized := mapper.NormalizeSaraminJob(job, raw.FetchedAt)
+		if err := geo.locateJob(ctx, &normalized); err != nil {
+			return err
+		}
+		if geo.assignRegion(&normalized) && job.ID != "" {
+			derivedIDs[job.ID] = struct{}{}
+		}
+		regionAgg.Add(normalized)
+		companyAgg.Add(normalized)
+		if normalized.Region == "" && job.ID != "" {
+			missingIDs[job.ID] = struct{}{}
+		}
+
+		result.jobs++
+		if windowStart.IsZero() || raw.FetchedAt.Before(windowStart) {
+			windowStart = raw.FetchedAt
+		}
+		if raw.FetchedAt.After(result.runAt) {
+			result.runAt = raw.FetchedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return rebuildResult{}, err
+	}
+	if result.jobs == 0 {
+		return rebuildResult{}, fmt.Errorf("no raw postings in %s between %s and %s",
+			cfg.paths.raw(), start.Format(dateLayout), end.Format(dateLayout))
+	}
+	result.missingRegions = len(missingIDs)
+	result.derived = len(derivedIDs)
+
+	now := result.runAt
+	stats := aggregate.ApplyRegionStats(regionAgg.Results(), regionStats)
+	staging, err := stageOutputs(cfg.paths)
+	if err != nil {
+		return rebuildResult{}, err
+	}
+	if cfg.outputs.has(outputRegionCounts) {
+		if err := writeRegionCounts(staging.regionCounts(), output.RegionCountsMeta{
+			RunAt:          now,
+			WindowStart:    windowStart,
+			WindowEnd:      now,
+			MissingRegions: result.missingRegions,
+			DerivedRegions: result.derived,
+			StatsSource:    statsSource(cfg.regionStats),
+		}, stats); err != nil {
+			return rebuildResult{}, err
+		}
+	}
+	currentCutoff := now.AddDate(0, 0, -cfg.currentDays)
+	if err := writeCompanyOutputs(staging, cfg.outputs, now, companyAgg.ActiveCompanies(currentCutoff)); err != nil {
+		return rebuildResult{}, err
+	}
+
+	state, err := jobstate.Load(cfg.paths.jobState())
+	if err != nil {
+		return rebuildResult{}, err
+	}
+	if err := writeStateOutputs(staging, cfg.outputs, now, currentCutoff, state); err != nil {
+		return rebuildResult{}, err
+	}
+	builtAt := time.Now()
+	if err := commitOutputs(cfg.paths, staging, "rebuild-"+runlog.FormatID(builtAt), builtAt); err != nil {
+		return rebuildResult{}, err
+	}
+	if err := geo.cache.Close(); err != nil {
+		return rebuildResult{}, err
+	}
+	if err := enrich.SaveLocations(cfg.paths.companyLocations(), geo.locations); err != nil {
+		return rebuildResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultServeAddr = "127.0.0.1:8080"
+
+//go:embed preview/index.html
+var previewPage []byte
+
+var serveContentTypes = map[string]string{
+	".json":    "application/json; charset=utf-8",
+	".geojson": "application/geo+json; charset=utf-8",
+	".jsonl":   "application/x-ndjson; charset=utf-8",
+	".html":    "text/html; charset=utf-8",
+}
+
+func runServeCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var (
+		dir  = fs.String("dir", a.paths.dir, "Bundle directory to serve (a data directory or a publish -out directory)")
+		addr = fs.String("addr", defaultServeAddr, "Address to listen on")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	root := strings.TrimSpace(*dir)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "bundle directory not found: %s\n", root)
+		return 2
+	}
+	fmt.Printf("serving %s at http://%s/\n", root, *addr)
+	if err := http.ListenAndServe(*addr, newPreviewHandler(root)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// newPreviewHandler serves the preview page at / and the public outputs of
+// dir under /data/, the layout the Pages site uses. Files that publish would
+// not copy are not served, so the preview shows exactly what would ship.
+func newPreviewHandler(dir string) http.Handler {
+	public := map[string]bool{}
+	for _, name := range newDataPaths(dir).publicFiles() {
+		public[name] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", "*")
+		header.Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		header.Set("Access-Control-Allow-Headers", "*")
+		header.Set("Cache-Control", "no-cache")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			header.Set("Allow", "GET, HEAD, OPTIONS")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := path.Clean(r.URL.Path)
+		if name == "/" || name == "/index.html" {
+			serveBytes(w, r, "index.html", time.Time{}, previewPage)
+			return
+		}
+		rel, ok := strings.CutPrefix(name, "/data/")
+		if !ok || !public[strings.SplitN(rel, "/", 2)[0]] {
+			http.NotFound(w, r)
+			return
+		}
+		file := filepath.Join(dir, filepath.FromSlash(rel))
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		payload, err := os.ReadFile(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		serveBytes(w, r, rel, info.ModTime(), payload)
+	})
+}
+
+// serveBytes writes payload with a content type from the file extension,
+// gzipped when the client accepts it.
+func serveBytes(w http.ResponseWriter, r *http.Request, name string, modTime time.Time, payload []byte) {
+	header := w.Header()
+	if contentType, ok := serveContentTypes[strings.ToLower(path.Ext(name))]; ok {
+		header.Set("Content-Type", contentType)
+	}
+	header.Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(r) {
+		http.ServeContent(w, r, name, modTime, bytes.NewReader(payload))
+		return
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(payload)
+	if err := zw.Close(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", http.DetectContentType(payload))
+	}
+	if !modTime.IsZero() {
+		header.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	header.Set("Content-Encoding", "gzip")
+	header.Set("Content-Length", fmt.Sprint(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	paths := newDataPaths(t.TempDir())
+	if err := os.WriteFile(paths.regionCounts(), []byte(`{"regions":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.jobState(), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(newPreviewHandler(paths.dir))
+	defer server.Close()
+
+	get := func(target, encoding string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Set explicitly so the transport does not decompress on its own.
+		req.Header.Set("Accept-Encoding", encoding)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("/data/region_counts.json", "gzip")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("region_counts.json: status %d encoding %q", resp.StatusCode, resp.Header.Get("Content-Encoding"))
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != `{"regions":[]}` {
+		t.Fatalf("body = %s", body)
+	}
+
+	resp = get("/", "identity")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Header.Get("Content-Encoding") != "" || !strings.Contains(string(body), "latest_companies.json") {
+		t.Fatalf("preview page: encoding %q, body without the data fetch", resp.Header.Get("Content-Encoding"))
+	}
+
+	for _, target := range []string{"/data/job_state.json", "/data/clusters/../job_state.json", "/data/latest_companies.json"} {
+		if resp := get(target, "identity"); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", target, resp.StatusCode)
+		}
+	}
+}
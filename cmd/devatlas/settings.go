@@ -0,0 +1,249 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"devatlas/config"
+	"devatlas/geocode"
+	"devatlas/saramin"
+)
+
+// app carries what the global flags resolve to into every command.
+type app struct {
+	paths  dataPaths
+	config config.Config
+}
+
+func defaultSettings() config.Settings {
+	return config.Settings{
+		Sr:          []string{"directhire"},
+		Sort:        "ud",
+		Window:      defaultWindow,
+		CurrentDays: defaultCurrentDays,
+		SweepDays:   defaultSweepDays,
+		MinInterval: defaultMinInterval,
+		ArchiveRaw:  true,
+		WideFilter:  wideFilterListed,
+		DailyQuota:  defaultDailyQuota,
+		Retry: config.Retry{
+			Attempts:  defaultRetryMaxTry,
+			BaseDelay: defaultRetryBase,
+			MaxDelay:  defaultRetryMax,
+		},
+		Geocode: config.Geocode{
+			Chain:         splitCSV(defaultGeocoders),
+			MinConfidence: geocode.DefaultMinConfidence,
+			TTL:           geocode.DefaultPositiveTTL,
+			NegativeTTL:   geocode.DefaultNegativeTTL,
+			CacheBackend:  "json",
+			Budget:        defaultGeocodeBudget,
+			Breaker:       defaultGeocodeBreaker,
+		},
+	}
+}
+
+// applyEnvSecrets lets environment variables override secrets from the
+// config file.
+func applyEnvSecrets(s *config.Settings) {
+	for _, secret := range []struct {
+		env string
+		dst *string
+	}{
+		{"SARAMIN_ACCESS_KEY", &s.AccessKey},
+		{"KAKAO_REST_API_KEY", &s.Geocode.KakaoKey},
+		{"VWORLD_API_KEY", &s.Geocode.VWorldKey},
+	} {
+		if value := strings.TrimSpace(os.Getenv(secret.env)); value != "" {
+			*secret.dst = value
+		}
+	}
+}
+
+// bindCollectFlags registers the collection flags with the current settings
+// as defaults, so a parsed flag overrides the config file.
+func bindCollectFlags(fs *flag.FlagSet, s *config.Settings) {
+	bindQueryFlags(fs, s)
+	fs.DurationVar(&s.Window, "window", s.Window, "Collection window when updated-min/max are omitted")
+	fs.IntVar(&s.CurrentDays, "current-days", s.CurrentDays, "Current hiring window in days")
+	fs.IntVar(&s.SweepDays, "sweep-days", s.SweepDays, "Widen the window to current-days once every this many days to find disappeared postings (0 = never)")
+	fs.StringVar(&s.RegionStats, "region-stats", s.RegionStats, "Region statistics CSV for normalization (default: bundled table)")
+	fs.Var(csvFlag{&s.Outputs}, "outputs", "Comma-separated outputs to write (default: all; "+strings.Join(outputNames, ", ")+")")
+	fs.BoolVar(&s.ArchiveRaw, "archive-raw", s.ArchiveRaw, "Archive raw postings under <data-dir>/raw for rebuild")
+	bindGeocodeFlags(fs, &s.Geocode)
+	fs.IntVar(&s.Geocode.Budget, "geocode-budget", s.Geocode.Budget, "Maximum geocode provider lookups per run (0 = unlimited)")
+	fs.IntVar(&s.Geocode.Breaker, "geocode-breaker", s.Geocode.Breaker, "Consecutive geocode failures before lookups pause (0 = never)")
+	fs.StringVar(&s.Geocode.AddressFile, "address-file", s.Geocode.AddressFile, "CSV of street addresses by job_id or company, overrides address-url (default <data-dir>/company_addresses.csv)")
+	fs.StringVar(&s.Geocode.AddressURL, "address-url", s.Geocode.AddressURL, "Address lookup URL template with {job_id}, {company} or {url} placeholders")
+}
+
+// bindQueryFlags registers what shapes the Saramin search and how fast it
+// is sent.
+func bindQueryFlags(fs *flag.FlagSet, s *config.Settings) {
+	fs.Var(csvFlag{&s.JobCodes}, "job-cd", "Comma-separated job codes (default: built-in developer codes)")
+	fs.Var(csvFlag{&s.JobMidCodes}, "job-mid-cd", "Comma-separated job mid codes; when set, queries by mid-code (2 = IT개발·데이터) and reports job-cd coverage")
+	fs.StringVar(&s.WideFilter, "wide-filter", s.WideFilter, "With job-mid-cd: keep only postings matching job-cd (listed) or every posting (all)")
+	fs.Var(csvFlag{&s.LocCodes}, "loc-cd", "Comma-separated location codes")
+	fs.Var(csvFlag{&s.Sr}, "sr", "Comma-separated sr filters")
+	fs.StringVar(&s.Sort, "sort", s.Sort, "Search sort order")
+	fs.Var(msFlag{&s.MinInterval}, "min-interval-ms", "Minimum interval between API calls in ms")
+	fs.IntVar(&s.Retry.Attempts, "retry-attempts", s.Retry.Attempts, "Max retry attempts for API calls")
+	fs.Var(msFlag{&s.Retry.BaseDelay}, "retry-base-ms", "Retry base delay in ms")
+	fs.Var(msFlag{&s.Retry.MaxDelay}, "retry-max-ms", "Retry max delay in ms")
+}
+
+func bindGeocodeFlags(fs *flag.FlagSet, g *config.Geocode) {
+	fs.Var(csvFlag{&g.Chain}, "geocoders", "Comma-separated geocoder chain order (gazetteer, kakao, vworld, nominatim)")
+	fs.Var(daysFlag{&g.TTL}, "geocode-ttl-days", "Days before a found geocode cache entry is looked up again (0 keeps forever)")
+	fs.Var(daysFlag{&g.NegativeTTL}, "geocode-negative-ttl-days", "Days before a not-found geocode cache entry is retried (0 keeps forever)")
+	fs.StringVar(&g.CacheBackend, "geocode-cache-backend", g.CacheBackend, "Geocode cache storage: json (single file) or journal (append-only log)")
+	fs.Float64Var(&g.MinConfidence, "geocode-min-confidence", g.MinConfidence, "Minimum confidence to accept a geocode result (0-1)")
+}
+
+// resolveSettings fills values that depend on other settings. In wide mode
+// the job-code list is what coverage is measured against.
+func resolveSettings(s *config.Settings) {
+	if len(s.JobCodes) == 0 {
+		s.JobCodes = append([]string(nil), defaultJobCodes...)
+	}
+}
+
+func newRunConfig(paths dataPaths, s config.Settings) (runConfig, error) {
+	resolveSettings(&s)
+	outputs, err := newOutputSet(s.Outputs)
+	if err != nil {
+		return runConfig{}, err
+	}
+	wideFilter := strings.ToLower(strings.TrimSpace(s.WideFilter))
+	if err := checkWideFilter(wideFilter); err != nil {
+		return runConfig{}, err
+	}
+	cfg := runConfig{
+		paths:       paths,
+		accessKey:   strings.TrimSpace(s.AccessKey),
+		jobCodes:    s.JobCodes,
+		jobMidCodes: s.JobMidCodes,
+		locCodes:    s.LocCodes,
+		sr:          s.Sr,
+		sort:        strings.TrimSpace(s.Sort),
+		window:      s.Window,
+		minInterval: max(0, s.MinInterval),
+		dailyQuota:  max(0, s.DailyQuota),
+		retry: saramin.RetryConfig{
+			MaxAttempts: max(1, s.Retry.Attempts),
+			BaseDelay:   max(0, s.Retry.BaseDelay),
+			MaxDelay:    max(0, s.Retry.MaxDelay),
+		},
+		currentDays: max(1, s.CurrentDays),
+		sweepDays:   max(0, s.SweepDays),
+		regionStats: strings.TrimSpace(s.RegionStats),
+		outputs:     outputs,
+		wideFilter:  wideFilter,
+		archiveRaw:  s.ArchiveRaw,
+		geocoders:   s.Geocode.Chain,
+		minGeoConf:  s.Geocode.MinConfidence,
+		geoTTL:      max(0, s.Geocode.TTL),
+		geoNegTTL:   max(0, s.Geocode.NegativeTTL),
+		geoBackend:  strings.TrimSpace(s.Geocode.CacheBackend),
+		geoBudget:   max(0, s.Geocode.Budget),
+		geoBreaker:  max(0, s.Geocode.Breaker),
+		addressFile: strings.TrimSpace(s.Geocode.AddressFile),
+		addressURL:  strings.TrimSpace(s.Geocode.AddressURL),
+		kakaoKey:    strings.TrimSpace(s.Geocode.KakaoKey),
+		vworldKey:   strings.TrimSpace(s.Geocode.VWorldKey),
+	}
+	if cfg.window <= 0 {
+		cfg.window = defaultWindow
+	}
+	if cfg.addressFile == "" {
+		cfg.addressFile = paths.addressFile()
+	}
+	return cfg, nil
+}
+
+func runConfigCommand(a app, args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: devatlas [-config file] [-profile name] config print [collect flags]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	settings := a.config.Settings
+	bindCollectFlags(fs, &settings)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if _, err := newRunConfig(a.paths, settings); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	resolveSettings(&settings)
+
+	effective := a.config
+	effective.Settings = settings
+	effective.DataDir = a.paths.dir
+	if err := config.Encode(os.Stdout, effective); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+type csvFlag struct {
+	values *[]string
+}
+
+func (f csvFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, ",")
+}
+
+func (f csvFlag) Set(value string) error {
+	*f.values = splitCSV(value)
+	return nil
+}
+
+type msFlag struct {
+	d *time.Duration
+}
+
+func (f msFlag) String() string {
+	if f.d == nil {
+		return "0"
+	}
+	return strconv.FormatInt(f.d.Milliseconds(), 10)
+}
+
+func (f msFlag) Set(value string) error {
+	ms, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*f.d = time.Duration(ms) * time.Millisecond
+	return nil
+}
+
+type daysFlag struct {
+	d *time.Duration
+}
+
+func (f daysFlag) String() string {
+	if f.d == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*f.d / (24 * time.Hour)))
+}
+
+func (f daysFlag) Set(value string) error {
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*f.d = time.Duration(days) * 24 * time.Hour
+	return nil
+}
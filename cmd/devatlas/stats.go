@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"devatlas/jobstate"
+	"devatlas/output"
+	"devatlas/rawstore"
+)
+
+func runStatsCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	var (
+		top        = fs.Int("top", 5, "Number of regions to list by job count")
+		geoBackend = fs.String("geocode-cache-backend", a.config.Settings.Geocode.CacheBackend, "Geocode cache storage: json (single file) or journal (append-only log)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := printStats(a.paths, max(0, *top), *geoBackend); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printStats(paths dataPaths, top int, geoBackend string) error {
+	var counts output.RegionCounts
+	if ok, err := readJSONFile(paths.regionCounts(), &counts); err != nil {
+		return fmt.Errorf("%s: %w", paths.regionCounts(), err)
+	} else if ok {
+		jobs := 0
+		for _, region := range counts.Regions {
+			jobs += region.JobCount
+		}
+		fmt.Printf("region_counts run_at=%s regions=%d jobs=%d missing_regions=%d derived_regions=%d\n",
+			counts.Meta.RunAt.Format(time.RFC3339), len(counts.Regions), jobs, counts.Meta.MissingRegions, counts.Meta.DerivedRegions)
+		regions := append(counts.Regions[:0:0], counts.Regions...)
+		sort.SliceStable(regions, func(i, j int) bool { return regions[i].JobCount > regions[j].JobCount })
+		for i, region := range regions {
+			if i >= top {
+				break
+			}
+			fmt.Printf("  %s jobs=%d companies=%d\n", region.Region, region.JobCount, region.CompanyCount)
+		}
+	}
+
+	var companies output.LatestCompanies
+	if ok, err := readJSONFile(paths.latestCompanies(), &companies); err != nil {
+		return fmt.Errorf("%s: %w", paths.latestCompanies(), err)
+	} else if ok {
+		byPrecision := map[string]int{}
+		for _, company := range companies.Companies {
+			byPrecision[company.Precision]++
+		}
+		fmt.Printf("companies total=%d address=%d sigungu=%d sido=%d\n", len(companies.Companies),
+			byPrecision["address"], byPrecision["sigungu"], byPrecision["sido"])
+	}
+
+	state, err := jobstate.Load(paths.jobState())
+	if err != nil {
+		return err
+	}
+	open := 0
+	for _, posting := range state.Jobs {
+		if !posting.Closed() {
+			open++
+		}
+	}
+	fmt.Printf("postings tracked=%d open=%d closed=%d\n", len(state.Jobs), open, len(state.Jobs)-open)
+
+	cache, err := loadGeocodeCache(paths, geoBackend)
+	if err != nil {
+		return err
+	}
+	found, pending := 0, 0
+	for _, entry := range cache.List() {
+		switch {
+		case entry.Pending:
+			pending++
+		case entry.Found:
+			found++
+		}
+	}
+	fmt.Printf("geocode_cache entries=%d found=%d not_found=%d pending=%d\n",
+		cache.Len(), found, cache.Len()-found-pending, pending)
+
+	files, err := rawstore.Files(paths.raw(), time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("raw_archive days=%d\n", len(files))
+	return nil
+}
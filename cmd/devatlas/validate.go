@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"devatlas/jsonschema"
+	"devatlas/mapper"
+	"devatlas/output"
+	"devatlas/timeseries"
+)
+
+// Loose bounding box around the peninsula and Jeju; markers outside it are
+// almost always geocoding mistakes.
+const (
+	minKoreaLat = 32.5
+	maxKoreaLat = 39.0
+	minKoreaLng = 124.0
+	maxKoreaLng = 132.0
+)
+
+const (
+	defaultTrailingDays    = 7
+	defaultMaxChange       = 0.5
+	defaultMinRegionJobs   = 10
+	defaultMaxMissingRatio = 0.2
+
+	// The trailing guards stay quiet until the series has this many earlier
+	// days, so the first runs of a new data directory can publish.
+	minGuardHistory = 3
+)
+
+// guards are the sanity checks that compare a run with the days before it.
+type guards struct {
+	trailingDays    int
+	maxChange       float64
+	minRegionJobs   int
+	maxMissingRatio float64
+}
+
+func runValidateCommand(a app, args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var (
+		enabled = fs.Bool("guards", true, "Compare the run with the trailing days of the time series")
+		g       guards
+	)
+	fs.IntVar(&g.trailingDays, "trailing-days", defaultTrailingDays, "Days of the time series to average for the guards")
+	fs.Float64Var(&g.maxChange, "max-change", defaultMaxChange, "Largest allowed change of total jobs against the trailing average (0.5 = 50%)")
+	fs.IntVar(&g.minRegionJobs, "min-region-jobs", defaultMinRegionJobs, "Trailing average above which a region may not drop to zero")
+	fs.Float64Var(&g.maxMissingRatio, "max-missing-ratio", defaultMaxMissingRatio, "Largest allowed share of postings without a region")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	problems := validateOutputs(a.paths)
+	if *enabled {
+		problems = append(problems, checkGuards(a.paths, g)...)
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("problems=%d\n", len(problems))
+		return 1
+	}
+	fmt.Println("ok")
+	return 0
+}
+
+// validateOutputs checks every public output against its schema and for
+// plausible values, and returns one message per problem found.
+func validateOutputs(paths dataPaths) []string {
+	var problems []string
+	report := func(path, format string, args ...any) {
+		problems = append(problems, filepath.Base(path)+": "+fmt.Sprintf(format, args...))
+	}
+
+	var counts output.RegionCounts
+	if path := paths.regionCounts(); readOutput(path, "region_counts", &counts, report, true) {
+		if counts.Meta.RunAt.IsZero() {
+			report(path, "meta.run_at is missing")
+		}
+		seen := map[string]bool{}
+		for _, region := range counts.Regions {
+			if region.Region != mapper.NormalizeRegionName(region.Region) {
+				report(path, "region %q is not a canonical name", region.Region)
+			}
+			if seen[region.Region] {
+				report(path, "region %q appears twice", region.Region)
+			}
+			seen[region.Region] = true
+			if region.JobCount < 0 || region.CompanyCount < 0 {
+				report(path, "region %q has negative counts", region.Region)
+			}
+		}
+	}
+
+	var companies output.LatestCompanies
+	if path := paths.latestCompanies(); readOutput(path, "latest_companies", &companies, report, true) {
+		for _, company := range companies.Companies {
+			if company.Lat == 0 && company.Lng == 0 {
+				continue
+			}
+			if !insideKorea(company.Lat, company.Lng) {
+				report(path, "company %q at %.4f,%.4f is outside Korea", company.Name, company.Lat, company.Lng)
+			}
+		}
+	}
+
+	var collection output.CompaniesGeoJSON
+	if path := paths.companiesGeoJSON(); readOutput(path, "latest_companies.geojson", &collection, report, false) {
+		if collection.Type != "FeatureCollection" {
+			report(path, "type is %q, want FeatureCollection", collection.Type)
+		}
+		for _, feature := range collection.Features {
+			if feature.Geometry.Type != "Point" {
+				report(path, "feature %q has geometry %q", feature.Properties.Name, feature.Geometry.Type)
+			}
+		}
+	}
+
+	tiles, _ := filepath.Glob(filepath.Join(paths.clusters(), "z*.json"))
+	sort.Strings(tiles)
+	for _, path := range tiles {
+		var tile output.ClusterTile
+		readOutput(path, "cluster_tile", &tile, report, true)
+	}
+
+	var lifetimes output.PostingLifetimes
+	readOutput(paths.postingLifetimes(), "posting_lifetimes", &lifetimes, report, false)
+	var engagement output.Engagement
+	readOutput(paths.engagement(), "engagement", &engagement, report, false)
+	var series json.RawMessage
+	readOutput(paths.timeseries(), "region_timeseries", &series, report, false)
+
+	if _, err := os.Stat(paths.manifest()); err == nil {
+		if _, err := verifyManifest(paths); err != nil {
+			report(paths.manifest(), "%v", err)
+		}
+	}
+	return problems
+}
+
+// readOutput checks path against the schema of the named document and
+// decodes it into v.
+func readOutput(path, schema string, v any, report func(path, format string, args ...any), required bool) bool {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			report(path, "%v", err)
+		} else if required {
+			report(path, "missing")
+		}
+		return false
+	}
+	s, err := outputSchema(schema)
+	if err != nil {
+		report(path, "%v", err)
+		return false
+	}
+	for _, problem := range s.Validate(payload) {
+		report(path, "%s", problem)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		report(path, "%v", err)
+		return false
+	}
+	return true
+}
+
+func outputSchema(name string) (*jsonschema.Schema, error) {
+	doc, ok := output.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s", name)
+	}
+	return output.Schema(doc), nil
+}
+
+// checkGuards compares region_counts.json with the trailing days of the
+// time series: the total may not swing by more than maxChange, a region with
+// a steady trailing count may not drop to zero, and the share of postings
+// without a region must stay under maxMissingRatio.
+func checkGuards(paths dataPaths, g guards) []string {
+	var counts output.RegionCounts
+	if ok, err := readJSONFile(paths.regionCounts(), &counts); !ok || err != nil {
+		// validateOutputs already reported it.
+		return nil
+	}
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, "guard: "+fmt.Sprintf(format, args...))
+	}
+
+	total := 0
+	current := map[string]int{}
+	for _, region := range counts.Regions {
+		total += region.JobCount
+		current[region.Region] = region.JobCount
+	}
+	if missing := counts.Meta.MissingRegions; total+missing > 0 {
+		if ratio := float64(missing) / float64(total+missing); ratio > g.maxMissingRatio {
+			report("%.1f%% of postings have no region (limit %.1f%%)", ratio*100, g.maxMissingRatio*100)
+		}
+	}
+
+	series, err := timeseries.Load(paths.timeseries())
+	if err != nil {
+		report("%s: %v", filepath.Base(paths.timeseries()), err)
+		return problems
+	}
+	today := counts.Meta.RunAt.Format(dateLayout)
+	byDate := map[string]map[string]int{}
+	for _, point := range series.Regions {
+		if point.Source != "" || point.Date >= today {
+			continue
+		}
+		if byDate[point.Date] == nil {
+			byDate[point.Date] = map[string]int{}
+		}
+		byDate[point.Date][point.Region] += point.JobCount
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	if len(dates) > g.trailingDays {
+		dates = dates[:g.trailingDays]
+	}
+	if len(dates) < minGuardHistory {
+		return problems
+	}
+
+	trailingTotal := 0.0
+	regionTotals := map[string]float64{}
+	for _, date := range dates {
+		for region, jobs := range byDate[date] {
+			trailingTotal += float64(jobs)
+			regionTotals[region] += float64(jobs)
+		}
+	}
+	days := float64(len(dates))
+	if average := trailingTotal / days; average > 0 {
+		if change := (float64(total) - average) / average; math.Abs(change) > g.maxChange {
+			report("total jobs %d is %+.0f%% against the %d-day average %.0f (limit %.0f%%)",
+				total, change*100, len(dates), average, g.maxChange*100)
+		}
+	}
+	regions := make([]string, 0, len(regionTotals))
+	for region := range regionTotals {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		average := regionTotals[region] / days
+		if current[region] == 0 && average >= float64(g.minRegionJobs) {
+			report("region %q dropped to 0 from a %d-day average of %.0f", region, len(dates), average)
+		}
+	}
+	return problems
+}
+
+func insideKorea(lat, lng float64) bool {
+	return lat >= minKoreaLat && lat <= maxKoreaLat && lng >= minKoreaLng && lng <= maxKoreaLng
+}
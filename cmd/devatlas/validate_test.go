@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/jobstate"
+	"devatlas/output"
+	"devatlas/timeseries"
+)
+
+func TestValidateOutputsAcceptsWrittenOutputs(t *testing.T) {
+	paths := newDataPaths(t.TempDir())
+	runAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	stats := []aggregate.RegionCount{{Region: "서울", JobCount: 2, CompanyCount: 1, CompanyHHI: 1}}
+	companies := []aggregate.CompanyRecord{{Name: "a", Lat: 37.5, Lng: 127.0, Region: "서울", Precision: "sido", LastSeen: runAt}}
+
+	if err := writeRegionCounts(paths.regionCounts(), output.RegionCountsMeta{RunAt: runAt, WindowStart: runAt, WindowEnd: runAt}, stats); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCompanyOutputs(paths, nil, runAt, companies); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeStateOutputs(paths, nil, runAt, runAt, &jobstate.Store{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := timeseries.Save(paths.timeseries(), &timeseries.Series{Meta: timeseries.Meta{SchemaVersion: output.SchemaVersion}}); err != nil {
+		t.Fatal(err)
+	}
+	if problems := validateOutputs(paths); len(problems) > 0 {
+		t.Fatalf("problems = %q", problems)
+	}
+
+	if err := writeRegionCounts(paths.regionCounts(), output.RegionCountsMeta{RunAt: runAt}, nil); err != nil {
+		t.Fatal(err)
+	}
+	problems := validateOutputs(paths)
+	if len(problems) != 1 || !strings.Contains(problems[0], "/regions: want array, got null") {
+		t.Fatalf("problems for empty regions = %q", problems)
+	}
+}
+
+func TestCheckGuards(t *testing.T) {
+	runAt := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	history := []timeseries.RegionPoint{}
+	for day := 1; day <= 9; day++ {
+		date := runAt.AddDate(0, 0, -day).Format(dateLayout)
+		history = append(history,
+			timeseries.RegionPoint{Date: date, Region: "서울", JobCount: 80},
+			timeseries.RegionPoint{Date: date, Region: "부산", JobCount: 20},
+			// Backfilled days count by publication date and are not compared.
+			timeseries.RegionPoint{Date: runAt.AddDate(0, -1, -day).Format(dateLayout), Region: "서울", JobCount: 1, Source: timeseries.SourceBackfill},
+		)
+	}
+	tests := []struct {
+		name    string
+		regions []aggregate.RegionCount
+		missing int
+		history []timeseries.RegionPoint
+		want    []string
+	}{
+		{"steady", []aggregate.RegionCount{{Region: "서울", JobCount: 90}, {Region: "부산", JobCount: 25}}, 5, history, nil},
+		{"collapse", []aggregate.RegionCount{{Region: "서울", JobCount: 30}}, 0, history, []string{"total jobs 30", `region "부산" dropped to 0`}},
+		{"missing regions", []aggregate.RegionCount{{Region: "서울", JobCount: 80}, {Region: "부산", JobCount: 20}}, 40, history, []string{"28.6% of postings have no region"}},
+		{"short history", []aggregate.RegionCount{{Region: "서울", JobCount: 1}}, 0, history[:6], nil},
+	}
+	for _, tt := range tests {
+		paths := newDataPaths(t.TempDir())
+		if err := writeRegionCounts(paths.regionCounts(), output.RegionCountsMeta{RunAt: runAt, MissingRegions: tt.missing}, tt.regions); err != nil {
+			t.Fatal(err)
+		}
+		if err := timeseries.Save(paths.timeseries(), &timeseries.Series{Regions: tt.history}); err != nil {
+			t.Fatal(err)
+		}
+		got := checkGuards(paths, guards{trailingDays: 7, maxChange: 0.5, minRegionJobs: 10, maxMissingRatio: 0.2})
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: checkGuards = %q, want %d problems", tt.name, got, len(tt.want))
+			continue
+		}
+		for i := range got {
+			if !strings.Contains(got[i], tt.want[i]) {
+				t.Errorf("%s: problem %d = %q, want %q", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
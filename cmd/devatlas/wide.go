@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devatlas/fsutil"
+	"devatlas/jobcode"
+	"devatlas/saramin"
+)
+
+const (
+	wideFilterListed = "listed"
+	wideFilterAll    = "all"
+)
+
+// wideMode classifies postings from a job_mid_cd query against the job-code
+// list and decides which of them reach the aggregates.
+type wideMode struct {
+	coverage *jobcode.Coverage
+	keepAll  bool
+}
+
+type coverageMeta struct {
+	RunAt       time.Time `json:"run_at"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	JobMidCodes []string  `json:"job_mid_cd"`
+	JobCodes    int       `json:"job_cd_count"`
+	Filter      string    `json:"filter"`
+}
+
+type coverageOutput struct {
+	Meta coverageMeta `json:"meta"`
+	jobcode.CoverageReport
+}
+
+// newWideMode returns nil unless the run queries by job mid-code.
+func newWideMode(cfg runConfig) *wideMode {
+	if len(cfg.jobMidCodes) == 0 {
+		return nil
+	}
+	return &wideMode{
+		coverage: jobcode.NewCoverage(cfg.jobCodes),
+		keepAll:  cfg.wideFilter == wideFilterAll,
+	}
+}
+
+func (w *wideMode) keep(job saramin.Job) bool {
+	if w == nil {
+		return true
+	}
+	covered := w.coverage.Add(job.ID, splitCSV(job.Position.JobCode.Code), splitCSV(job.Position.JobCode.Name))
+	return covered || w.keepAll
+}
+
+func (w *wideMode) report() *jobcode.CoverageReport {
+	if w == nil {
+		return nil
+	}
+	report := w.coverage.Report()
+	return &report
+}
+
+func checkWideFilter(filter string) error {
+	switch filter {
+	case wideFilterListed, wideFilterAll:
+		return nil
+	default:
+		return fmt.Errorf("unknown wide filter %q (want %s or %s)", filter, wideFilterListed, wideFilterAll)
+	}
+}
+
+func writeCoverageReport(path string, meta coverageMeta, report jobcode.CoverageReport) error {
+	payload, err := json.Marshal(coverageOutput{Meta: meta, CoverageReport: report})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
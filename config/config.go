@@ -0,0 +1,303 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings is everything a profile can set. Fields are matched to config
+// keys by their toml tag; a ",secret" tag keeps the value out of Encode.
+type Settings struct {
+	AccessKey   string        `toml:"access_key,secret"`
+	JobCodes    []string      `toml:"job_cd"`
+	JobMidCodes []string      `toml:"job_mid_cd"`
+	WideFilter  string        `toml:"wide_filter"`
+	LocCodes    []string      `toml:"loc_cd"`
+	Sr          []string      `toml:"sr"`
+	Sort        string        `toml:"sort"`
+	Window      time.Duration `toml:"window"`
+	CurrentDays int           `toml:"current_days"`
+	SweepDays   int           `toml:"sweep_days"`
+	MinInterval time.Duration `toml:"min_interval"`
+	DailyQuota  int           `toml:"daily_quota"`
+	RegionStats string        `toml:"region_stats"`
+	Outputs     []string      `toml:"outputs"`
+	ArchiveRaw  bool          `toml:"archive_raw"`
+	Retry       Retry         `toml:"retry"`
+	Geocode     Geocode       `toml:"geocode"`
+}
+
+type Retry struct {
+	Attempts  int           `toml:"attempts"`
+	BaseDelay time.Duration `toml:"base_delay"`
+	MaxDelay  time.Duration `toml:"max_delay"`
+}
+
+type Geocode struct {
+	Chain         []string      `toml:"chain"`
+	MinConfidence float64       `toml:"min_confidence"`
+	TTL           time.Duration `toml:"ttl"`
+	NegativeTTL   time.Duration `toml:"negative_ttl"`
+	CacheBackend  string        `toml:"cache_backend"`
+	Budget        int           `toml:"budget"`
+	Breaker       int           `toml:"breaker"`
+	AddressFile   string        `toml:"address_file"`
+	AddressURL    string        `toml:"address_url"`
+	KakaoKey      string        `toml:"kakao_key,secret"`
+	VWorldKey     string        `toml:"vworld_key,secret"`
+}
+
+// Config is a loaded config file with the selected profile applied on top
+// of the base settings.
+type Config struct {
+	Path     string
+	DataDir  string
+	Profile  string
+	Profiles []string
+	Settings Settings
+}
+
+// Load reads path and applies its top-level settings and then the named
+// profile (or the file's own `profile` key) over base. An empty path returns
+// base unchanged.
+func Load(path, profile string, base Settings) (Config, error) {
+	cfg := Config{Path: path, Profile: profile, Settings: base}
+	if strings.TrimSpace(path) == "" {
+		if profile != "" {
+			return Config{}, fmt.Errorf("config: profile %q needs a config file", profile)
+		}
+		return cfg, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+	return parse(file, path, profile, base)
+}
+
+func parse(r io.Reader, path, profile string, base Settings) (Config, error) {
+	cfg := Config{Path: path, Profile: profile, Settings: base}
+	root, err := parseTOML(r)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	profiles := map[string]any{}
+	if raw, ok := root["profiles"]; ok {
+		table, ok := raw.(map[string]any)
+		if !ok {
+			return Config{}, fmt.Errorf("config: %s: profiles must be a table", path)
+		}
+		profiles = table
+		delete(root, "profiles")
+	}
+	for name := range profiles {
+		cfg.Profiles = append(cfg.Profiles, name)
+	}
+	sort.Strings(cfg.Profiles)
+
+	for _, key := range []string{"data_dir", "profile"} {
+		raw, ok := root[key]
+		if !ok {
+			continue
+		}
+		value, ok := raw.(string)
+		if !ok {
+			return Config{}, fmt.Errorf("config: %s: %s must be a string", path, key)
+		}
+		if key == "data_dir" {
+			cfg.DataDir = value
+		} else if cfg.Profile == "" {
+			cfg.Profile = value
+		}
+		delete(root, key)
+	}
+
+	if err := decode(root, reflect.ValueOf(&cfg.Settings).Elem(), ""); err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+	if cfg.Profile == "" {
+		return cfg, nil
+	}
+	raw, ok := profiles[cfg.Profile]
+	if !ok {
+		return Config{}, fmt.Errorf("config: %s: unknown profile %q", path, cfg.Profile)
+	}
+	table, ok := raw.(map[string]any)
+	if !ok {
+		return Config{}, fmt.Errorf("config: %s: profile %q must be a table", path, cfg.Profile)
+	}
+	if err := decode(table, reflect.ValueOf(&cfg.Settings).Elem(), "profiles."+cfg.Profile+"."); err != nil {
+		return Config{}, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decode sets the fields of dst named by the keys of table and leaves the
+// rest alone, so later tables override earlier ones key by key.
+func decode(table map[string]any, dst reflect.Value, prefix string) error {
+	fields := map[string]reflect.Value{}
+	for i := 0; i < dst.NumField(); i++ {
+		name, _ := tagName(dst.Type().Field(i))
+		fields[name] = dst.Field(i)
+	}
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("unknown key %s%s", prefix, key)
+		}
+		if err := set(field, table[key]); err != nil {
+			return fmt.Errorf("%s%s: %w", prefix, key, err)
+		}
+	}
+	return nil
+}
+
+func set(field reflect.Value, raw any) error {
+	if field.Type() == durationType {
+		text, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("want a duration string such as \"500ms\"")
+		}
+		value, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Struct:
+		table, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("want a table")
+		}
+		return decode(table, field, "")
+	case reflect.String:
+		text, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("want a string")
+		}
+		field.SetString(text)
+	case reflect.Bool:
+		value, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("want true or false")
+		}
+		field.SetBool(value)
+	case reflect.Int:
+		value, ok := raw.(int64)
+		if !ok {
+			return fmt.Errorf("want an integer")
+		}
+		field.SetInt(value)
+	case reflect.Float64:
+		switch value := raw.(type) {
+		case float64:
+			field.SetFloat(value)
+		case int64:
+			field.SetFloat(float64(value))
+		default:
+			return fmt.Errorf("want a number")
+		}
+	case reflect.Slice:
+		items, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("want an array")
+		}
+		out := make([]string, 0, len(items))
+		for _, item := range items {
+			switch value := item.(type) {
+			case string:
+				out = append(out, value)
+			case int64:
+				out = append(out, strconv.FormatInt(value, 10))
+			default:
+				return fmt.Errorf("want an array of strings")
+			}
+		}
+		field.Set(reflect.ValueOf(out))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func tagName(field reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	return name, opts == "secret"
+}
+
+// Encode writes the effective settings of cfg as TOML with secrets masked.
+// The output loads back as a config file without profiles.
+func Encode(w io.Writer, cfg Config) error {
+	var b strings.Builder
+	if cfg.Path != "" {
+		fmt.Fprintf(&b, "# config: %s\n", cfg.Path)
+	}
+	if cfg.Profile != "" {
+		fmt.Fprintf(&b, "# profile: %s\n", cfg.Profile)
+	}
+	if cfg.DataDir != "" {
+		fmt.Fprintf(&b, "data_dir = %s\n", strconv.Quote(cfg.DataDir))
+	}
+	encodeTable(&b, reflect.ValueOf(cfg.Settings), "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func encodeTable(b *strings.Builder, v reflect.Value, header string) {
+	var tables []int
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			tables = append(tables, i)
+			continue
+		}
+		name, secret := tagName(v.Type().Field(i))
+		fmt.Fprintf(b, "%s = %s\n", name, encodeValue(field, secret))
+	}
+	for _, i := range tables {
+		name, _ := tagName(v.Type().Field(i))
+		if header != "" {
+			name = header + "." + name
+		}
+		fmt.Fprintf(b, "\n[%s]\n", name)
+		encodeTable(b, v.Field(i), name)
+	}
+}
+
+func encodeValue(field reflect.Value, secret bool) string {
+	if field.Type() == durationType {
+		return strconv.Quote(time.Duration(field.Int()).String())
+	}
+	switch field.Kind() {
+	case reflect.String:
+		if secret && field.String() != "" {
+			return strconv.Quote("<redacted>")
+		}
+		return strconv.Quote(field.String())
+	case reflect.Slice:
+		items := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			items = append(items, strconv.Quote(field.Index(i).String()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `
+# shared settings
+data_dir = "runs/data"
+profile = "daily"
+sr = ["directhire"]
+sort = "ud"
+window = "24h"
+job_cd = [
+  84, 92, # backend, frontend
+  "2232",
+]
+
+[retry]
+attempts = 3
+base_delay = "500ms"
+
+[geocode]
+chain = ["gazetteer", "kakao"]
+kakao_key = "secret-key"
+
+[profiles.daily]
+current_days = 21
+
+[profiles.wide]
+job_cd = []
+job_mid_cd = ["2"]
+loc_cd = ['101000']
+outputs = ["region_counts"]
+
+[profiles.wide.geocode]
+chain = ["gazetteer"]
+min_confidence = 1
+`
+
+func TestParseAppliesProfileOverBase(t *testing.T) {
+	base := Settings{CurrentDays: 7, Retry: Retry{MaxDelay: 5 * time.Second}}
+
+	daily, err := parse(strings.NewReader(sample), "sample.toml", "", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily.Profile != "daily" || daily.DataDir != "runs/data" || daily.Settings.CurrentDays != 21 {
+		t.Fatalf("daily = %+v", daily)
+	}
+	if want := []string{"84", "92", "2232"}; !reflect.DeepEqual(daily.Settings.JobCodes, want) {
+		t.Fatalf("job_cd = %v, want %v", daily.Settings.JobCodes, want)
+	}
+	if daily.Settings.Retry.BaseDelay != 500*time.Millisecond || daily.Settings.Retry.MaxDelay != 5*time.Second {
+		t.Fatalf("retry = %+v", daily.Settings.Retry)
+	}
+
+	wide, err := parse(strings.NewReader(sample), "sample.toml", "wide", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := wide.Settings
+	if len(s.JobCodes) != 0 || !reflect.DeepEqual(s.JobMidCodes, []string{"2"}) || !reflect.DeepEqual(s.LocCodes, []string{"101000"}) {
+		t.Fatalf("wide codes = %v %v %v", s.JobCodes, s.JobMidCodes, s.LocCodes)
+	}
+	if !reflect.DeepEqual(s.Geocode.Chain, []string{"gazetteer"}) || s.Geocode.MinConfidence != 1 || s.Geocode.KakaoKey != "secret-key" {
+		t.Fatalf("wide geocode = %+v", s.Geocode)
+	}
+	if s.CurrentDays != 7 {
+		t.Fatalf("current_days = %d, want base value", s.CurrentDays)
+	}
+}
+
+func TestParseRejectsMistakes(t *testing.T) {
+	tests := []struct {
+		name, input, profile, want string
+	}{
+		{"unknown key", "job_code = [84]", "", "unknown key job_code"},
+		{"unknown profile key", "[profiles.x]\nwindow_days = 1", "x", "unknown key profiles.x.window_days"},
+		{"unknown profile", "sort = \"ud\"", "nightly", `unknown profile "nightly"`},
+		{"bad duration", "window = 24", "", "window: want a duration"},
+		{"duplicate key", "sort = \"ud\"\nsort = \"pd\"", "", "duplicate key"},
+		{"open array", "sr = [\"a\",", "", "unterminated array"},
+	}
+	for _, tt := range tests {
+		_, err := parse(strings.NewReader(tt.input), "bad.toml", tt.profile, Settings{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEncodeRedactsSecretsAndRoundTrips(t *testing.T) {
+	cfg, err := parse(strings.NewReader(sample), "sample.toml", "wide", Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := Encode(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "secret-key") {
+		t.Fatalf("secret leaked:\n%s", out.String())
+	}
+
+	again, err := parse(strings.NewReader(out.String()), "printed.toml", "", Settings{})
+	if err != nil {
+		t.Fatalf("printed config does not parse: %v\n%s", err, out.String())
+	}
+	again.Settings.Geocode.KakaoKey = cfg.Settings.Geocode.KakaoKey
+	if !reflect.DeepEqual(again.Settings, cfg.Settings) {
+		t.Fatalf("round trip = %+v, want %+v", again.Settings, cfg.Settings)
+	}
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseTOML reads the subset of TOML that config files use: tables with
+// dotted headers, bare or quoted keys, strings, integers, floats, booleans
+// and arrays of those, which may span several lines.
+func parseTOML(r io.Reader) (map[string]any, error) {
+	root := map[string]any{}
+	current := root
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	var pending strings.Builder
+	pendingLine := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if pending.Len() > 0 {
+			pending.WriteString(" ")
+			pending.WriteString(line)
+			if !balanced(pending.String()) {
+				continue
+			}
+			line = pending.String()
+			pending.Reset()
+		} else if line == "" {
+			continue
+		} else if !strings.HasPrefix(line, "[") && !balanced(line) {
+			pending.WriteString(line)
+			pendingLine = lineNo
+			continue
+		} else {
+			pendingLine = lineNo
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header", pendingLine)
+			}
+			keys, err := splitKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+			}
+			table, err := descend(root, keys)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+			}
+			current = table
+			continue
+		}
+
+		eq := indexOutsideQuotes(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", pendingLine)
+		}
+		keys, err := splitKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+		}
+		value, err := parseValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+		}
+		table, err := descend(current, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+		}
+		last := keys[len(keys)-1]
+		if _, exists := table[last]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", pendingLine, last)
+		}
+		table[last] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if pending.Len() > 0 {
+		return nil, fmt.Errorf("line %d: unterminated array", pendingLine)
+	}
+	return root, nil
+}
+
+func descend(table map[string]any, keys []string) (map[string]any, error) {
+	for _, key := range keys {
+		next, ok := table[key]
+		if !ok {
+			child := map[string]any{}
+			table[key] = child
+			table = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("key %q is not a table", key)
+		}
+		table = child
+	}
+	return table, nil
+}
+
+func splitKey(raw string) ([]string, error) {
+	var keys []string
+	for raw != "" {
+		var key string
+		if raw[0] == '"' || raw[0] == '\'' {
+			end := strings.IndexByte(raw[1:], raw[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			key, raw = raw[1:end+1], strings.TrimSpace(raw[end+2:])
+		} else {
+			dot := strings.IndexByte(raw, '.')
+			if dot < 0 {
+				dot = len(raw)
+			}
+			key, raw = strings.TrimSpace(raw[:dot]), raw[dot:]
+			if key == "" || strings.ContainsAny(key, " \t") {
+				return nil, fmt.Errorf("invalid key %q", key)
+			}
+		}
+		keys = append(keys, key)
+		if raw == "" {
+			break
+		}
+		if raw[0] != '.' {
+			return nil, fmt.Errorf("invalid key near %q", raw)
+		}
+		raw = strings.TrimSpace(raw[1:])
+		if raw == "" {
+			return nil, fmt.Errorf("key ends with a dot")
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return keys, nil
+}
+
+func parseValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw[0] == '"':
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' || strings.Contains(raw[1:len(raw)-1], "'") {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw[0] == '[':
+		if raw[len(raw)-1] != ']' {
+			return nil, fmt.Errorf("invalid array %s", raw)
+		}
+		return parseArray(strings.TrimSpace(raw[1 : len(raw)-1]))
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid value %s", raw)
+}
+
+func parseArray(body string) ([]any, error) {
+	values := []any{}
+	for body != "" {
+		end := indexOutsideQuotes(body, ',')
+		if end < 0 {
+			end = len(body)
+		}
+		item := strings.TrimSpace(body[:end])
+		if item != "" {
+			if strings.HasPrefix(item, "[") {
+				return nil, fmt.Errorf("nested arrays are not supported")
+			}
+			value, err := parseValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if end == len(body) {
+			break
+		}
+		body = strings.TrimSpace(body[end+1:])
+	}
+	return values, nil
+}
+
+func stripComment(line string) string {
+	if i := indexOutsideQuotes(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func balanced(line string) bool {
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"', '\'':
+			i = skipString(line, i)
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func indexOutsideQuotes(s string, target byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			i = skipString(s, i)
+		case target:
+			return i
+		}
+	}
+	return -1
+}
+
+// skipString returns the index of the quote closing the string that opens
+// at s[start], or the last index when it is unterminated.
+func skipString(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return len(s) - 1
+}
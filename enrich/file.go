@@ -0,0 +1,121 @@
+package enrich
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"devatlas/model"
+)
+
+type FileSource struct {
+	byJobID   map[string]string
+	byCompany map[string]string
+}
+
+func LoadFileSource(path string) (*FileSource, error) {
+	source := &FileSource{byJobID: map[string]string{}, byCompany: map[string]string{}}
+	if strings.TrimSpace(path) == "" {
+		return source, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return source, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	if err := source.read(file); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func ParseFileSource(r io.Reader) (*FileSource, error) {
+	source := &FileSource{byJobID: map[string]string{}, byCompany: map[string]string{}}
+	if err := source.read(r); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *FileSource) read(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	addressCol, ok := columns["address"]
+	if !ok {
+		return errors.New("enrich: address file needs an address column")
+	}
+	jobCol, hasJob := columns["job_id"]
+	companyCol, hasCompany := columns["company"]
+	if !hasJob && !hasCompany {
+		return errors.New("enrich: address file needs a job_id or company column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		address := field(record, addressCol)
+		if address == "" {
+			continue
+		}
+		if hasJob {
+			if id := field(record, jobCol); id != "" {
+				s.byJobID[id] = address
+				continue
+			}
+		}
+		if hasCompany {
+			if key := companyKey(field(record, companyCol)); key != "" {
+				s.byCompany[key] = address
+			}
+		}
+	}
+}
+
+func (s *FileSource) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.byJobID) + len(s.byCompany)
+}
+
+func (s *FileSource) Address(ctx context.Context, job model.NormalizedJob) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	if address, ok := s.byJobID[job.SourceJobID]; ok {
+		return address, nil
+	}
+	return s.byCompany[companyKey(job.CompanyName)], nil
+}
+
+func field(record []string, col int) string {
+	if col >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[col])
+}
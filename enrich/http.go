@@ -0,0 +1,113 @@
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"devatlas/model"
+)
+
+type HTTPSource struct {
+	template   string
+	httpClient *http.Client
+	userAgent  string
+
+	mu   sync.Mutex
+	seen map[string]string
+}
+
+type HTTPOption func(*HTTPSource)
+
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(s *HTTPSource) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// NewHTTPSource fetches addresses from a URL template. {job_id}, {company}
+// and {url} are replaced with the query-escaped posting fields, and the
+// response must be a JSON object with an "address" (or "road_address") field.
+func NewHTTPSource(template string, opts ...HTTPOption) *HTTPSource {
+	s := &HTTPSource{
+		template:   strings.TrimSpace(template),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		userAgent:  "devatlas/0.1",
+		seen:       map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *HTTPSource) Address(ctx context.Context, job model.NormalizedJob) (string, error) {
+	if s == nil || s.template == "" {
+		return "", nil
+	}
+	endpoint := strings.NewReplacer(
+		"{job_id}", url.QueryEscape(job.SourceJobID),
+		"{company}", url.QueryEscape(job.CompanyName),
+		"{url}", url.QueryEscape(job.SourceURL),
+	).Replace(s.template)
+
+	s.mu.Lock()
+	address, ok := s.seen[endpoint]
+	s.mu.Unlock()
+	if ok {
+		return address, nil
+	}
+
+	address, err := s.fetch(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.seen[endpoint] = address
+	s.mu.Unlock()
+	return address, nil
+}
+
+func (s *HTTPSource) fetch(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if strings.TrimSpace(s.userAgent) != "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("enrich: address source status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Address     string `json:"address"`
+		RoadAddress string `json:"road_address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", errors.Join(errors.New("enrich: address source returned invalid JSON"), err)
+	}
+	if address := strings.TrimSpace(payload.RoadAddress); address != "" {
+		return address, nil
+	}
+	return strings.TrimSpace(payload.Address), nil
+}
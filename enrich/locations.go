@@ -0,0 +1,110 @@
+package enrich
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"devatlas/fsutil"
+)
+
+// Location is where a company's office in one region was found. A company
+// hiring in several regions has one location per region, so a posting is
+// never placed at an office in another region.
+type Location struct {
+	Company   string    `json:"company"`
+	Region    string    `json:"region"`
+	Address   string    `json:"address"`
+	Lat       float64   `json:"lat"`
+	Lng       float64   `json:"lng"`
+	Provider  string    `json:"provider,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Locations struct {
+	mu      sync.RWMutex
+	entries map[string]Location
+}
+
+type locationsFile struct {
+	Companies map[string]Location `json:"companies"`
+}
+
+func LoadLocations(path string) (*Locations, error) {
+	locations := &Locations{entries: map[string]Location{}}
+	if strings.TrimSpace(path) == "" {
+		return locations, nil
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return locations, nil
+		}
+		return nil, err
+	}
+	var file locationsFile
+	if err := json.Unmarshal(payload, &file); err != nil {
+		return nil, err
+	}
+	for _, location := range file.Companies {
+		// Entries saved before locations were kept per region cannot be
+		// told apart by region, so they are dropped and found again.
+		if key := locationKey(location.Company, location.Region); key != "" {
+			locations.entries[key] = location
+		}
+	}
+	return locations, nil
+}
+
+func SaveLocations(path string, locations *Locations) error {
+	if locations == nil || strings.TrimSpace(path) == "" {
+		return nil
+	}
+	locations.mu.RLock()
+	payload, err := json.Marshal(locationsFile{Companies: locations.entries})
+	locations.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func (l *Locations) Get(company, region string) (Location, bool) {
+	if l == nil {
+		return Location{}, false
+	}
+	key := locationKey(company, region)
+	if key == "" {
+		return Location{}, false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	location, ok := l.entries[key]
+	return location, ok
+}
+
+func (l *Locations) Set(location Location) {
+	if l == nil {
+		return
+	}
+	key := locationKey(location.Company, location.Region)
+	if key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries == nil {
+		l.entries = map[string]Location{}
+	}
+	l.entries[key] = location
+}
+
+func locationKey(company, region string) string {
+	company, region = companyKey(company), strings.TrimSpace(region)
+	if company == "" || region == "" {
+		return ""
+	}
+	return company + "|" + region
+}
@@ -0,0 +1,78 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"devatlas/model"
+)
+
+type AddressSource interface {
+	Address(ctx context.Context, job model.NormalizedJob) (string, error)
+}
+
+type Enricher struct {
+	sources []AddressSource
+
+	mu       sync.Mutex
+	failures int
+}
+
+func NewEnricher(sources ...AddressSource) *Enricher {
+	enricher := &Enricher{}
+	for _, source := range sources {
+		if source != nil {
+			enricher.sources = append(enricher.sources, source)
+		}
+	}
+	return enricher
+}
+
+func (e *Enricher) Len() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.sources)
+}
+
+func (e *Enricher) Address(ctx context.Context, job model.NormalizedJob) (string, error) {
+	if e == nil {
+		return "", nil
+	}
+	var errs []error
+	for _, source := range e.sources {
+		address, err := source.Address(ctx, job)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", ctxErr
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if address = strings.TrimSpace(address); address != "" {
+			return address, nil
+		}
+	}
+	if len(errs) > 0 {
+		e.mu.Lock()
+		e.failures++
+		e.mu.Unlock()
+		return "", errors.Join(errs...)
+	}
+	return "", nil
+}
+
+func (e *Enricher) Failures() int {
+	if e == nil {
+		return 0
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.failures
+}
+
+func companyKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
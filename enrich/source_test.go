@@ -0,0 +1,51 @@
+package enrich
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"devatlas/model"
+)
+
+func TestEnricherPrefersFileOverride(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("company") != "(주)데브아틀라스" {
+			t.Errorf("company = %q", r.URL.Query().Get("company"))
+		}
+		w.Write([]byte(`{"address":"서울 강남구 테헤란로 1"}`))
+	}))
+	defer server.Close()
+
+	file, err := ParseFileSource(strings.NewReader("job_id,company,address\n42,,부산 해운대구 센텀중앙로 55\n,Example Corp,대전 유성구 대학로 99\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enricher := NewEnricher(file, NewHTTPSource(server.URL+"?company={company}"))
+
+	tests := []struct {
+		job  model.NormalizedJob
+		want string
+	}{
+		{model.NormalizedJob{SourceJobID: "42", CompanyName: "(주)데브아틀라스"}, "부산 해운대구 센텀중앙로 55"},
+		{model.NormalizedJob{SourceJobID: "7", CompanyName: "example  corp"}, "대전 유성구 대학로 99"},
+		{model.NormalizedJob{SourceJobID: "8", CompanyName: "(주)데브아틀라스"}, "서울 강남구 테헤란로 1"},
+		{model.NormalizedJob{SourceJobID: "9", CompanyName: "(주)데브아틀라스"}, "서울 강남구 테헤란로 1"},
+	}
+	for _, tt := range tests {
+		got, err := enricher.Address(context.Background(), tt.job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Address(%s) = %q, want %q", tt.job.SourceJobID, got, tt.want)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("address source calls = %d, want 1", calls)
+	}
+}
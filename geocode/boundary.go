@@ -0,0 +1,104 @@
+package geocode
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+)
+
+// boundaries.json holds coarse sido outlines that extend into coastal waters;
+// overlaps are resolved in favour of the smaller region (metropolitan enclaves).
+//
+//go:embed boundaries.json
+var bundledBoundaries []byte
+
+type ring [][2]float64
+
+type regionShape struct {
+	name  string
+	rings []ring
+	area  float64
+}
+
+type Boundaries struct {
+	regions []regionShape
+}
+
+func NewBoundaries() (*Boundaries, error) {
+	return ParseBoundaries(bundledBoundaries)
+}
+
+func ParseBoundaries(payload []byte) (*Boundaries, error) {
+	var raw map[string][]ring
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+	b := &Boundaries{regions: make([]regionShape, 0, len(raw))}
+	for name, rings := range raw {
+		shape := regionShape{name: name, rings: rings}
+		for _, r := range rings {
+			shape.area += r.area()
+		}
+		b.regions = append(b.regions, shape)
+	}
+	return b, nil
+}
+
+func (b *Boundaries) Locate(lat, lng float64) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	best := -1
+	for i, region := range b.regions {
+		if !region.contains(lng, lat) {
+			continue
+		}
+		if best < 0 || region.area < b.regions[best].area {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	return b.regions[best].name, true
+}
+
+func (s regionShape) contains(x, y float64) bool {
+	for _, r := range s.rings {
+		if r.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ring) contains(x, y float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (r ring) area() float64 {
+	var sum float64
+	for i := range r {
+		next := r[(i+1)%len(r)]
+		sum += r[i][0]*next[1] - next[0]*r[i][1]
+	}
+	return math.Abs(sum) / 2
+}
+
+func distanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
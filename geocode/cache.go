@@ -3,42 +3,88 @@ package geocode
 import (
 	"encoding/json"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"devatlas/fsutil"
+)
+
+const (
+	DefaultPositiveTTL = 180 * 24 * time.Hour
+	DefaultNegativeTTL = 7 * 24 * time.Hour
+
+	DefaultCheckpointEvery    = 50
+	DefaultCheckpointInterval = time.Minute
 )
 
 type CacheEntry struct {
-	Query     string    `json:"query"`
-	Lat       float64   `json:"lat"`
-	Lng       float64   `json:"lng"`
-	Found     bool      `json:"found"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Query           string    `json:"query"`
+	Region          string    `json:"region,omitempty"`
+	Lat             float64   `json:"lat"`
+	Lng             float64   `json:"lng"`
+	Found           bool      `json:"found"`
+	Provider        string    `json:"provider,omitempty"`
+	ProviderVersion string    `json:"provider_version,omitempty"`
+	Sido            string    `json:"sido,omitempty"`
+	Confidence      float64   `json:"confidence,omitempty"`
+	Flag            string    `json:"flag,omitempty"`
+	Pending         bool      `json:"pending,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type Cache struct {
+	mu      sync.RWMutex
+	flushMu sync.Mutex
+	entries map[string]CacheEntry
+
+	path               string
+	journal            *journal
+	dirty              int
+	lastFlush          time.Time
+	checkpointEvery    int
+	checkpointInterval time.Duration
+	now                func() time.Time
+}
+
+type cacheFile struct {
 	Entries map[string]CacheEntry `json:"entries"`
 }
 
-func LoadCache(path string) (*Cache, error) {
+type CacheOption func(*Cache)
+
+func WithCheckpoint(every int, interval time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.checkpointEvery = every
+		c.checkpointInterval = interval
+	}
+}
+
+func OpenCache(path string, opts ...CacheOption) (*Cache, error) {
+	cache := newCache(path, opts...)
 	if strings.TrimSpace(path) == "" {
-		return &Cache{Entries: map[string]CacheEntry{}}, nil
+		return cache, nil
 	}
 	payload, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &Cache{Entries: map[string]CacheEntry{}}, nil
+			return cache, nil
 		}
 		return nil, err
 	}
-	var cache Cache
-	if err := json.Unmarshal(payload, &cache); err != nil {
+	var file cacheFile
+	if err := json.Unmarshal(payload, &file); err != nil {
 		return nil, err
 	}
-	if cache.Entries == nil {
-		cache.Entries = map[string]CacheEntry{}
+	if file.Entries != nil {
+		cache.entries = file.Entries
 	}
-	return &cache, nil
+	return cache, nil
+}
+
+func LoadCache(path string) (*Cache, error) {
+	return OpenCache(path)
 }
 
 func SaveCache(path string, cache *Cache) error {
@@ -48,17 +94,35 @@ func SaveCache(path string, cache *Cache) error {
 	if strings.TrimSpace(path) == "" {
 		return nil
 	}
-	dir := filepath.Dir(path)
-	if dir != "." && dir != "" {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return err
-		}
-	}
-	payload, err := json.Marshal(cache)
+	cache.mu.RLock()
+	payload, err := json.Marshal(cacheFile{Entries: cache.entries})
+	cache.mu.RUnlock()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, append(payload, '\n'), 0o644)
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func newCache(path string, opts ...CacheOption) *Cache {
+	cache := &Cache{
+		entries:            map[string]CacheEntry{},
+		path:               path,
+		checkpointEvery:    DefaultCheckpointEvery,
+		checkpointInterval: DefaultCheckpointInterval,
+		now:                time.Now,
+	}
+	for _, opt := range opts {
+		opt(cache)
+	}
+	cache.lastFlush = cache.clock()
+	return cache
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
 }
 
 func (c *Cache) Get(query string) (CacheEntry, bool) {
@@ -66,7 +130,9 @@ func (c *Cache) Get(query string) (CacheEntry, bool) {
 		return CacheEntry{}, false
 	}
 	key := normalizeQuery(query)
-	entry, ok := c.Entries[key]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
 	return entry, ok
 }
 
@@ -74,12 +140,133 @@ func (c *Cache) Set(query string, entry CacheEntry) {
 	if c == nil {
 		return
 	}
-	if c.Entries == nil {
-		c.Entries = map[string]CacheEntry{}
-	}
 	key := normalizeQuery(query)
 	entry.Query = query
-	c.Entries[key] = entry
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]CacheEntry{}
+	}
+	c.entries[key] = entry
+	c.dirty++
+	c.journal.append(entry)
+}
+
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+func (c *Cache) List() []CacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	out := make([]CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		out = append(out, entry)
+	}
+	c.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].UpdatedAt.Equal(out[j].UpdatedAt) {
+			return out[i].Query < out[j].Query
+		}
+		return out[i].UpdatedAt.Before(out[j].UpdatedAt)
+	})
+	return out
+}
+
+func (c *Cache) Checkpoint() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	due := c.dirty > 0 &&
+		((c.checkpointEvery > 0 && c.dirty >= c.checkpointEvery) ||
+			(c.checkpointInterval > 0 && c.clock().Sub(c.lastFlush) >= c.checkpointInterval))
+	c.mu.RUnlock()
+	if !due {
+		return nil
+	}
+	return c.Flush()
+}
+
+func (c *Cache) Flush() error {
+	if c == nil {
+		return nil
+	}
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	if c.journal != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err := c.journal.sync(); err != nil {
+			return err
+		}
+		c.dirty = 0
+		c.lastFlush = c.clock()
+		return nil
+	}
+
+	c.mu.RLock()
+	dirty := c.dirty
+	c.mu.RUnlock()
+	if dirty == 0 {
+		return nil
+	}
+	if err := SaveCache(c.path, c); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.dirty -= min(dirty, c.dirty)
+	c.lastFlush = c.clock()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	if c.journal == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.journal.close(c.entries)
+}
+
+func (e CacheEntry) Expired(now time.Time, positiveTTL, negativeTTL time.Duration) bool {
+	ttl := positiveTTL
+	if !e.Found {
+		ttl = negativeTTL
+	}
+	if ttl <= 0 {
+		return false
+	}
+	return !now.Before(e.UpdatedAt.Add(ttl))
+}
+
+func ProviderVersion(provider string) string {
+	switch provider {
+	case ProviderGazetteer:
+		return gazetteerVersion()
+	case ProviderKakao:
+		return KakaoVersion
+	case ProviderVWorld:
+		return VWorldVersion
+	case ProviderNominatim:
+		return NominatimVersion
+	default:
+		return ""
+	}
 }
 
 func normalizeQuery(query string) string {
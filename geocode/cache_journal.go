@@ -0,0 +1,162 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"devatlas/fsutil"
+)
+
+type journal struct {
+	path  string
+	file  *os.File
+	lines int
+	err   error
+}
+
+func OpenJournalCache(path string, opts ...CacheOption) (*Cache, error) {
+	cache := newCache(path, opts...)
+	if strings.TrimSpace(path) == "" {
+		return cache, nil
+	}
+	lines, validSize, err := replayJournal(path, cache.entries)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := file.Stat(); err == nil && info.Size() > validSize {
+		if err := file.Truncate(validSize); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	cache.journal = &journal{path: path, file: file, lines: lines}
+	return cache, nil
+}
+
+// LoadJournalCache replays a journal for reading only. The file is never
+// opened for writing, so a torn final line is skipped rather than cut off,
+// and nothing set on the returned cache is saved.
+func LoadJournalCache(path string) (*Cache, error) {
+	cache := newCache("")
+	if strings.TrimSpace(path) == "" {
+		return cache, nil
+	}
+	if _, _, err := replayJournal(path, cache.entries); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func replayJournal(path string, entries map[string]CacheEntry) (int, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lines := 0
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// An interrupted append leaves a torn final line; it is cut off
+			// so the next append starts on a clean line.
+			return lines, offset, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		lines++
+		if text := bytes.TrimSpace(line); len(text) > 0 {
+			var entry CacheEntry
+			if err := json.Unmarshal(text, &entry); err != nil {
+				if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+					return lines - 1, offset, nil
+				}
+				return 0, 0, fmt.Errorf("geocode: journal %s line %d: %w", path, lines, err)
+			}
+			entries[normalizeQuery(entry.Query)] = entry
+		}
+		offset += int64(len(line))
+	}
+}
+
+func (j *journal) append(entry CacheEntry) {
+	if j == nil || j.file == nil || j.err != nil {
+		return
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		j.err = err
+		return
+	}
+	if _, err := j.file.Write(append(payload, '\n')); err != nil {
+		j.err = err
+		return
+	}
+	j.lines++
+}
+
+func (j *journal) sync() error {
+	if j == nil || j.file == nil {
+		return nil
+	}
+	if j.err != nil {
+		return j.err
+	}
+	return j.file.Sync()
+}
+
+func (j *journal) close(entries map[string]CacheEntry) error {
+	if j == nil || j.file == nil {
+		return nil
+	}
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	j.file = nil
+	if j.lines <= 2*len(entries) {
+		return nil
+	}
+	return compactJournal(j.path, entries)
+}
+
+func compactJournal(path string, entries map[string]CacheEntry) error {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buf []byte
+	for _, key := range keys {
+		payload, err := json.Marshal(entries[key])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, payload...)
+		buf = append(buf, '\n')
+	}
+	return fsutil.WriteFileAtomic(path, buf, 0o644)
+}
@@ -0,0 +1,198 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResolverRetriesExpiredNegativeEntries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"documents":[{"x":"129.0756","y":"35.1796"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cache := &Cache{}
+	cache.Set("부산", CacheEntry{Found: false, UpdatedAt: now.Add(-2 * time.Hour)})
+	cache.Set("부산 해운대구", CacheEntry{Found: false, UpdatedAt: now.Add(-30 * time.Minute)})
+
+	resolver := NewResolver(NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0)), cache,
+		WithCacheTTL(24*time.Hour, time.Hour))
+	resolver.now = func() time.Time { return now }
+
+	if result, cached, err := resolver.Resolve(context.Background(), "부산", ""); err != nil || cached || !result.Found {
+		t.Fatalf("Resolve(expired) = %+v cached=%v err=%v, want a fresh hit", result, cached, err)
+	}
+	if _, cached, _ := resolver.Resolve(context.Background(), "부산 해운대구", ""); !cached {
+		t.Fatal("Resolve(fresh negative) should come from the cache")
+	}
+	if calls != 1 {
+		t.Fatalf("provider calls = %d, want 1", calls)
+	}
+	entry, _ := cache.Get("부산")
+	if entry.ProviderVersion != KakaoVersion || !entry.UpdatedAt.Equal(now) {
+		t.Fatalf("cache entry = %+v, want provider version %q at %s", entry, KakaoVersion, now)
+	}
+}
+
+func TestResolverRefreshKeepsFoundEntryOnMiss(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cache := &Cache{}
+	found := CacheEntry{Query: "부산 해운대구 우동", Lat: 35.1686, Lng: 129.1350, Found: true,
+		Provider: ProviderKakao, ProviderVersion: KakaoVersion, UpdatedAt: now.Add(-48 * time.Hour)}
+	cache.Set(found.Query, found)
+	cache.Set("부산 없는동", CacheEntry{Query: "부산 없는동", Found: false, UpdatedAt: now.Add(-2 * time.Hour)})
+
+	resolver := NewResolver(NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0)), cache,
+		WithCacheTTL(24*time.Hour, time.Hour))
+	resolver.now = func() time.Time { return now }
+
+	result, err := resolver.Refresh(context.Background(), found)
+	if err != nil || !result.Found || result.Lat != found.Lat || result.Lng != found.Lng {
+		t.Fatalf("Refresh = %+v err=%v, want the cached coordinates", result, err)
+	}
+	entry, _ := cache.Get(found.Query)
+	if !entry.Found || entry.Lat != found.Lat || entry.Provider != ProviderKakao || !entry.UpdatedAt.Equal(now) {
+		t.Fatalf("cache entry = %+v, want the found entry checked at %s", entry, now)
+	}
+
+	// A negative entry is simply renewed.
+	entry, _ = cache.Get("부산 없는동")
+	if _, err := resolver.Refresh(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := cache.Get("부산 없는동"); entry.Found || !entry.UpdatedAt.Equal(now) {
+		t.Fatalf("cache entry = %+v, want a negative entry at %s", entry, now)
+	}
+}
+
+func TestResolverTreatsOldProviderVersionAsStale(t *testing.T) {
+	now := time.Now()
+	resolver := NewResolver(NewChain(), &Cache{})
+	entry := CacheEntry{Found: true, Provider: ProviderKakao, ProviderVersion: "local-v1", UpdatedAt: now}
+	if !resolver.Stale(entry) {
+		t.Fatal("entry from an older provider version should be stale")
+	}
+	entry.ProviderVersion = KakaoVersion
+	if resolver.Stale(entry) {
+		t.Fatal("fresh entry with the current provider version should not be stale")
+	}
+}
+
+func TestJournalCacheReplaysAndDropsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	cache, err := OpenJournalCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("부산", CacheEntry{Found: true, Lat: 35.1796, Lng: 129.0756})
+	cache.Set("서울", CacheEntry{Found: false})
+	cache.Set("서울", CacheEntry{Found: true, Lat: 37.5665, Lng: 126.978})
+	if err := cache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	cache.journal.file.Write([]byte(`{"query":"대구","fou`))
+	cache.journal.file.Close()
+
+	reopened, err := OpenJournalCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", reopened.Len())
+	}
+	if entry, _ := reopened.Get("서울"); !entry.Found {
+		t.Fatalf("서울 = %+v, want the later entry", entry)
+	}
+	reopened.Set("대구", CacheEntry{Found: true})
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	final, err := OpenJournalCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer final.Close()
+	if _, ok := final.Get("대구"); !ok || final.Len() != 3 {
+		t.Fatalf("Len = %d, want 3 entries including 대구", final.Len())
+	}
+}
+
+func TestLoadJournalCacheLeavesFileAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	torn := "{\"query\":\"부산\",\"found\":true,\"lat\":35.1796,\"lng\":129.0756}\n{\"query\":\"대구\",\"fou"
+	if err := os.WriteFile(path, []byte(torn), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := LoadJournalCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("부산"); !ok || cache.Len() != 1 {
+		t.Fatalf("Len = %d, want only 부산", cache.Len())
+	}
+	cache.Set("서울", CacheEntry{Found: true})
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != torn {
+		t.Fatalf("journal = %q, want it unchanged", payload)
+	}
+}
+
+func TestCacheCheckpointWritesAtomically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := OpenCache(path, WithCheckpoint(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				cache.Set(fmt.Sprintf("q-%d-%d", i, j), CacheEntry{Found: true})
+				if err := cache.Checkpoint(); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 40 {
+		t.Fatalf("Len = %d, want 40", reopened.Len())
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*.tmp"))
+	if len(matches) != 0 {
+		t.Fatalf("leftover temp files: %v", matches)
+	}
+}
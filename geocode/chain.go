@@ -0,0 +1,64 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type Chain struct {
+	providers []Geocoder
+}
+
+func NewChain(providers ...Geocoder) *Chain {
+	chain := &Chain{}
+	for _, provider := range providers {
+		if provider != nil {
+			chain.providers = append(chain.providers, provider)
+		}
+	}
+	return chain
+}
+
+func (c *Chain) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.providers)
+}
+
+func (c *Chain) Geocode(ctx context.Context, query string) (Result, error) {
+	return c.GeocodeAccepting(ctx, query, nil)
+}
+
+// GeocodeAccepting asks each provider in turn and returns the first found
+// result accept takes. It reports not found only when every provider
+// answered; otherwise it returns the provider errors.
+func (c *Chain) GeocodeAccepting(ctx context.Context, query string, accept func(Result) bool) (Result, error) {
+	if c == nil || len(c.providers) == 0 {
+		return Result{}, errors.New("geocode: chain has no providers")
+	}
+	if strings.TrimSpace(query) == "" {
+		return Result{Found: false}, nil
+	}
+	var errs []error
+	for _, provider := range c.providers {
+		result, err := provider.Geocode(ctx, query)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return Result{}, ctxErr
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if result.Found && (accept == nil || accept(result)) {
+			return result, nil
+		}
+	}
+	// A provider that failed might have found the query, so the miss is not
+	// final: report the errors and let the resolver retry later.
+	if len(errs) > 0 {
+		return Result{}, errors.Join(errs...)
+	}
+	return Result{Found: false}, nil
+}
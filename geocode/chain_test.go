@@ -0,0 +1,110 @@
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChainFallsThroughProviders(t *testing.T) {
+	kakao := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "KakaoAK kakao-key" {
+			t.Errorf("kakao authorization = %q", got)
+		}
+		if r.URL.Path != "/v2/local/search/address.json" {
+			t.Errorf("kakao path = %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"documents":[],"meta":{"total_count":0}}`))
+	}))
+	defer kakao.Close()
+
+	vworld := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("key"); got != "vworld-key" {
+			t.Errorf("vworld key = %q", got)
+		}
+		if r.URL.Query().Get("type") == "ROAD" {
+			w.Write([]byte(`{"response":{"status":"NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(`{"response":{"status":"OK","result":{"point":{"x":"127.0276","y":"37.4979"}}}}`))
+	}))
+	defer vworld.Close()
+
+	chain := NewChain(
+		NewKakao("kakao-key", WithKakaoBaseURL(kakao.URL), WithKakaoMinInterval(0)),
+		NewVWorld("vworld-key", WithVWorldBaseURL(vworld.URL), WithVWorldMinInterval(0)),
+	)
+	result, err := chain.Geocode(context.Background(), "서울 강남구 테헤란로 152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.Provider != ProviderVWorld {
+		t.Fatalf("result = %+v, want vworld hit", result)
+	}
+	if result.Lat != 37.4979 || result.Lng != 127.0276 {
+		t.Fatalf("coords = %v,%v", result.Lat, result.Lng)
+	}
+}
+
+func TestChainReturnsErrorWhenAllProvidersFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	chain := NewChain(
+		NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0)),
+		NewNominatim(WithBaseURL(server.URL), WithMinInterval(0)),
+	)
+	if _, err := chain.Geocode(context.Background(), "부산 해운대구"); err == nil {
+		t.Fatal("expected error when every provider fails")
+	}
+}
+
+func TestChainReturnsErrorWhenAProviderFailsAndNoneFinds(t *testing.T) {
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer empty.Close()
+
+	chain := NewChain(
+		NewKakao("key", WithKakaoBaseURL(limited.URL), WithKakaoMinInterval(0)),
+		NewNominatim(WithBaseURL(empty.URL), WithMinInterval(0)),
+	)
+	if _, err := chain.Geocode(context.Background(), "부산 해운대구 센텀중앙로 79"); err == nil {
+		t.Fatal("expected an error when one provider fails and the rest find nothing")
+	}
+
+	// The resolver must not cache that as a negative answer.
+	cache := &Cache{}
+	resolver := NewResolver(chain, cache)
+	if _, _, err := resolver.Resolve(context.Background(), "부산 해운대구 센텀중앙로 79", "부산"); err == nil {
+		t.Fatal("Resolve hid the provider error")
+	}
+	entry, ok := cache.Get("부산 해운대구 센텀중앙로 79")
+	if !ok || !entry.Pending {
+		t.Fatalf("cache entry = %+v, want a pending retry", entry)
+	}
+}
+
+func TestResolverRecordsProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"documents":[{"x":"129.0756","y":"35.1796"}]}`))
+	}))
+	defer server.Close()
+
+	cache := &Cache{}
+	resolver := NewResolver(NewChain(NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0))), cache)
+	if _, _, err := resolver.Resolve(context.Background(), "부산", "부산"); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := cache.Get("부산")
+	if !ok || entry.Provider != ProviderKakao {
+		t.Fatalf("cache entry = %+v, want provider %q", entry, ProviderKakao)
+	}
+}
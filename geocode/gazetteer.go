@@ -0,0 +1,309 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"devatlas/mapper"
+)
+
+const (
+	ProviderGazetteer = "gazetteer"
+
+	LevelSido         = "sido"
+	LevelSigungu      = "sigungu"
+	LevelEupmyeondong = "eupmyeondong"
+)
+
+//go:embed gazetteer.tsv
+var bundledGazetteer []byte
+
+func gazetteerVersion() string {
+	sum := sha256.Sum256(bundledGazetteer)
+	return "tsv-" + hex.EncodeToString(sum[:6])
+}
+
+var sidoOfficialNames = map[string]string{
+	"서울": "서울특별시",
+	"부산": "부산광역시",
+	"대구": "대구광역시",
+	"인천": "인천광역시",
+	"광주": "광주광역시",
+	"대전": "대전광역시",
+	"울산": "울산광역시",
+	"세종": "세종특별자치시",
+	"경기": "경기도",
+	"강원": "강원특별자치도",
+	"충북": "충청북도",
+	"충남": "충청남도",
+	"전북": "전북특별자치도",
+	"전남": "전라남도",
+	"경북": "경상북도",
+	"경남": "경상남도",
+	"제주": "제주특별자치도",
+}
+
+type sigunguAlias struct {
+	sido    string
+	sigungu string
+}
+
+var sigunguAliases = map[sigunguAlias]sigunguAlias{
+	{"인천", "남구"}:  {"인천", "미추홀구"},
+	{"경북", "군위군"}: {"대구", "군위군"},
+	{"충북", "청원군"}: {"충북", "청주시 청원구"},
+	{"경남", "마산시"}: {"경남", "창원시 마산합포구"},
+	{"경남", "진해시"}: {"경남", "창원시 진해구"},
+	{"경기", "여주군"}: {"경기", "여주시"},
+	{"충남", "당진군"}: {"충남", "당진시"},
+}
+
+// Place is a sido, sigungu or eupmyeondong centroid. Only selected
+// eupmyeondong are listed; others fall through to the network providers, and
+// Approximate answers them with their sigungu.
+type Place struct {
+	Sido         string
+	Sigungu      string
+	Eupmyeondong string
+	Lat          float64
+	Lng          float64
+}
+
+func (p Place) Level() string {
+	switch {
+	case p.Eupmyeondong != "":
+		return LevelEupmyeondong
+	case p.Sigungu != "":
+		return LevelSigungu
+	default:
+		return LevelSido
+	}
+}
+
+func (p Place) SidoName() string {
+	if name, ok := sidoOfficialNames[p.Sido]; ok {
+		return name
+	}
+	return p.Sido
+}
+
+type Gazetteer struct {
+	places     map[placeKey]Place
+	bySigungu  map[string][]Place
+	subdivided map[string]bool
+}
+
+type placeKey struct {
+	sido         string
+	sigungu      string
+	eupmyeondong string
+}
+
+func NewGazetteer() (*Gazetteer, error) {
+	return LoadGazetteer(bytes.NewReader(bundledGazetteer))
+}
+
+func LoadGazetteer(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{
+		places:     map[placeKey]Place{},
+		bySigungu:  map[string][]Place{},
+		subdivided: map[string]bool{},
+	}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "sido\t") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("geocode: gazetteer line %d: expected 5 fields", line)
+		}
+		lat, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geocode: gazetteer line %d: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geocode: gazetteer line %d: %w", line, err)
+		}
+		place := Place{
+			Sido:         strings.TrimSpace(fields[0]),
+			Sigungu:      strings.TrimSpace(fields[1]),
+			Eupmyeondong: strings.TrimSpace(fields[2]),
+			Lat:          lat,
+			Lng:          lng,
+		}
+		g.places[placeKey{place.Sido, place.Sigungu, place.Eupmyeondong}] = place
+		if place.Sigungu != "" && place.Eupmyeondong == "" {
+			g.bySigungu[place.Sigungu] = append(g.bySigungu[place.Sigungu], place)
+			g.subdivided[place.Sido] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *Gazetteer) Geocode(ctx context.Context, query string) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	place, unmatched, ok := g.match(query)
+	if !ok || unmatched > 0 {
+		return Result{Found: false, Provider: ProviderGazetteer}, nil
+	}
+	return Result{Lat: place.Lat, Lng: place.Lng, Found: true, Provider: ProviderGazetteer}, nil
+}
+
+func (g *Gazetteer) Approximate(query string) (Place, bool) {
+	place, _, ok := g.match(query)
+	return place, ok
+}
+
+func (g *Gazetteer) Sido(region string) (Place, bool) {
+	if g == nil {
+		return Place{}, false
+	}
+	place, ok := g.places[placeKey{sido: mapper.NormalizeRegionName(region)}]
+	return place, ok
+}
+
+func (g *Gazetteer) match(query string) (Place, int, bool) {
+	if g == nil {
+		return Place{}, 0, false
+	}
+	tokens := tokenizePlace(query)
+	if len(tokens) == 0 {
+		return Place{}, 0, false
+	}
+
+	var place Place
+	rest := tokens
+	if sido := mapper.NormalizeRegionName(tokens[0]); sido != "" {
+		found, ok := g.places[placeKey{sido: sido}]
+		if !ok {
+			return Place{}, 0, false
+		}
+		place = found
+		if _, isSigungu := g.places[placeKey{sido: sido, sigungu: tokens[0]}]; !isSigungu {
+			rest = tokens[1:]
+		}
+	} else {
+		found, ok := g.uniqueSigungu(tokens)
+		if !ok {
+			return Place{}, 0, false
+		}
+		place = g.places[placeKey{sido: found.Sido}]
+	}
+
+	if sigungu, consumed, ok := g.matchSigungu(place.Sido, rest); ok {
+		place = sigungu
+		rest = rest[consumed:]
+	} else {
+		return place, len(rest), true
+	}
+
+	if len(rest) > 0 {
+		if emd, ok := g.places[placeKey{place.Sido, place.Sigungu, normalizeDong(rest[0])}]; ok {
+			place = emd
+			rest = rest[1:]
+		}
+	}
+	return place, len(rest), true
+}
+
+func (g *Gazetteer) matchSigungu(sido string, tokens []string) (Place, int, bool) {
+	for n := min(2, len(tokens)); n >= 1; n-- {
+		name := strings.Join(tokens[:n], " ")
+		if place, ok := g.places[placeKey{sido: sido, sigungu: name}]; ok {
+			return place, n, true
+		}
+		if alias, ok := sigunguAliases[sigunguAlias{sido, name}]; ok {
+			if place, ok := g.places[placeKey{sido: alias.sido, sigungu: alias.sigungu}]; ok {
+				return place, n, true
+			}
+		}
+	}
+	return Place{}, 0, false
+}
+
+func (g *Gazetteer) uniqueSigungu(tokens []string) (Place, bool) {
+	for n := min(2, len(tokens)); n >= 1; n-- {
+		candidates := g.bySigungu[strings.Join(tokens[:n], " ")]
+		if len(candidates) == 1 {
+			return candidates[0], true
+		}
+	}
+	return Place{}, false
+}
+
+func tokenizePlace(query string) []string {
+	replaced := strings.NewReplacer(">", " ", "/", " ", ",", " ").Replace(query)
+	fields := strings.Fields(replaced)
+	out := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if idx := strings.Index(field, "("); idx >= 0 {
+			field = field[:idx]
+		}
+		field = strings.TrimSpace(field)
+		if field == "" || field == "전체" || field == "외" || field == "등" {
+			continue
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+// normalizeDong maps a numbered administrative dong (역삼1동) to its legal
+// dong (역삼동), the name the table lists.
+func normalizeDong(name string) string {
+	trimmed := strings.TrimSuffix(name, "동")
+	if trimmed == name {
+		return name
+	}
+	base := strings.TrimRight(trimmed, "0123456789")
+	if base == "" {
+		return name
+	}
+	return base + "동"
+}
+
+func (g *Gazetteer) Nearest(lat, lng float64, sido string) (Place, float64, bool) {
+	if g == nil {
+		return Place{}, 0, false
+	}
+	var best Place
+	bestKm := math.Inf(1)
+	for key, place := range g.places {
+		if key.eupmyeondong != "" {
+			continue
+		}
+		if key.sigungu == "" && g.subdivided[key.sido] {
+			continue
+		}
+		if sido != "" && place.Sido != sido {
+			continue
+		}
+		if km := distanceKm(lat, lng, place.Lat, place.Lng); km < bestKm {
+			best = place
+			bestKm = km
+		}
+	}
+	if math.IsInf(bestKm, 1) {
+		return Place{}, 0, false
+	}
+	return best, bestKm, true
+}
@@ -0,0 +1,81 @@
+package geocode
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGazetteerGeocode(t *testing.T) {
+	gazetteer, err := NewGazetteer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query       string
+		wantFound   bool
+		wantSido    string
+		wantSigungu string
+		wantDong    string
+	}{
+		// Renamed provinces resolve under their current names.
+		{"강원도", true, "강원", "", ""},
+		{"강원특별자치도 춘천시", true, "강원", "춘천시", ""},
+		{"전라북도 전주시 완산구", true, "전북", "전주시 완산구", ""},
+		{"전북특별자치도 > 전주시 덕진구", true, "전북", "전주시 덕진구", ""},
+		{"서울 강남구", true, "서울", "강남구", ""},
+		{"경기 성남시 분당구", true, "경기", "성남시 분당구", ""},
+		// Renamed sigungu.
+		{"인천 남구", true, "인천", "미추홀구", ""},
+		// A sigungu name alone, when only one sido has it.
+		{"해운대구", true, "부산", "해운대구", ""},
+		{"중구", false, "", "", ""},
+		// Listed eupmyeondong, including numbered dongs, eup and myeon.
+		{"서울 강남구 역삼동", true, "서울", "강남구", "역삼동"},
+		{"서울특별시 강남구 역삼1동", true, "서울", "강남구", "역삼동"},
+		{"경기 성남시 분당구 삼평동", true, "경기", "성남시 분당구", "삼평동"},
+		{"경기도 이천시 부발읍", true, "경기", "이천시", "부발읍"},
+		{"해운대구 우동", true, "부산", "해운대구", "우동"},
+		// The same dong name under another sigungu is not confused.
+		{"경기 안산시 단원구 고잔동", true, "경기", "안산시 단원구", "고잔동"},
+		{"인천 남동구 고잔동", true, "인천", "남동구", "고잔동"},
+		// Street addresses and unlisted dongs are left to the network
+		// providers; Approximate still places them at the deepest match.
+		{"서울 강남구 역삼동 123-4", false, "서울", "강남구", "역삼동"},
+		{"서울 강남구 일원동", false, "서울", "강남구", ""},
+		{"서울 없는구", false, "서울", "", ""},
+	}
+	for _, tt := range tests {
+		result, err := gazetteer.Geocode(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if result.Found != tt.wantFound {
+			t.Errorf("%s: found = %v, want %v", tt.query, result.Found, tt.wantFound)
+		}
+		place, ok := gazetteer.Approximate(tt.query)
+		if ok != (tt.wantSido != "") || place.Sido != tt.wantSido || place.Sigungu != tt.wantSigungu || place.Eupmyeondong != tt.wantDong {
+			t.Errorf("%s: Approximate = %+v %v, want %s %s %s", tt.query, place, ok, tt.wantSido, tt.wantSigungu, tt.wantDong)
+		}
+		if result.Found && (result.Lat != place.Lat || result.Lng != place.Lng) {
+			t.Errorf("%s: Geocode = %v,%v, want the %s centroid", tt.query, result.Lat, result.Lng, place.Level())
+		}
+	}
+
+	if place, _ := gazetteer.Approximate("서울 금천구 가산동"); place.Level() != LevelEupmyeondong {
+		t.Errorf("Level = %q, want %s", place.Level(), LevelEupmyeondong)
+	}
+	// Reverse lookups resolve to sigungu, never to a listed dong.
+	if place, _, ok := gazetteer.Nearest(37.4680, 126.8970, "서울"); !ok || place.Sigungu != "금천구" || place.Eupmyeondong != "" {
+		t.Errorf("Nearest = %+v %v, want 서울 금천구", place, ok)
+	}
+
+	if place, _ := gazetteer.Approximate("강원도 춘천시"); place.SidoName() != "강원특별자치도" {
+		t.Errorf("SidoName = %q, want 강원특별자치도", place.SidoName())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gazetteer.Geocode(ctx, "서울 강남구"); err == nil {
+		t.Error("Geocode ignored a cancelled context")
+	}
+}
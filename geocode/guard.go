@@ -0,0 +1,76 @@
+package geocode
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBudgetExhausted = errors.New("geocode: request budget exhausted")
+	ErrCircuitOpen     = errors.New("geocode: circuit open after repeated failures")
+)
+
+const DefaultBreakerCooldown = 2 * time.Minute
+
+type Stats struct {
+	Lookups     int
+	Failures    int
+	Skipped     int
+	Pending     int
+	CircuitOpen bool
+}
+
+type guard struct {
+	mu        sync.Mutex
+	budget    int
+	threshold int
+	cooldown  time.Duration
+
+	stats       Stats
+	consecutive int
+	openedAt    time.Time
+	probing     bool
+}
+
+func (g *guard) acquire(now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.threshold > 0 && g.consecutive >= g.threshold {
+		if g.probing || now.Sub(g.openedAt) < g.cooldown {
+			g.stats.Skipped++
+			return ErrCircuitOpen
+		}
+		g.probing = true
+	}
+	if g.budget > 0 && g.stats.Lookups >= g.budget {
+		g.probing = false
+		g.stats.Skipped++
+		return ErrBudgetExhausted
+	}
+	g.stats.Lookups++
+	return nil
+}
+
+func (g *guard) record(now time.Time, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.probing = false
+	if err == nil {
+		g.consecutive = 0
+		return
+	}
+	g.stats.Failures++
+	g.consecutive++
+	if g.threshold > 0 && g.consecutive >= g.threshold {
+		g.openedAt = now
+	}
+}
+
+func (g *guard) snapshot() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stats := g.stats
+	stats.CircuitOpen = g.threshold > 0 && g.consecutive >= g.threshold
+	return stats
+}
@@ -0,0 +1,74 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolverDegradesOnProviderFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cache := &Cache{}
+	cache.Set("부산 해운대구", CacheEntry{Found: true, Lat: 35.1631, Lng: 129.1636, UpdatedAt: now.AddDate(-1, 0, 0)})
+	resolver := NewResolver(NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0)), cache,
+		WithCircuitBreaker(2, time.Minute))
+	resolver.now = func() time.Time { return now }
+
+	result, cached, err := resolver.Resolve(context.Background(), "부산 해운대구", "부산")
+	if err != nil || !cached || !result.Found {
+		t.Fatalf("expired entry should be served when the provider fails: %+v cached=%v err=%v", result, cached, err)
+	}
+	if _, _, err := resolver.Resolve(context.Background(), "서울 강남구", "서울"); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if _, _, err := resolver.Resolve(context.Background(), "대전 유성구", "대전"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want circuit open", err)
+	}
+	if calls != 2 {
+		t.Fatalf("provider calls = %d, want 2", calls)
+	}
+
+	stats := resolver.Stats()
+	if !stats.CircuitOpen || stats.Failures != 2 || stats.Skipped != 1 || stats.Pending != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if entry, _ := cache.Get("부산 해운대구"); !entry.Found {
+		t.Fatal("a failed refresh must not replace a found entry")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := resolver.ResolvePending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("provider calls after cooldown = %d, want a single probe", calls)
+	}
+}
+
+func TestResolverBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"documents":[]}`))
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(NewKakao("key", WithKakaoBaseURL(server.URL), WithKakaoMinInterval(0)), &Cache{}, WithBudget(1))
+	if _, _, err := resolver.Resolve(context.Background(), "a", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resolver.Resolve(context.Background(), "b", ""); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("err = %v, want budget exhausted", err)
+	}
+	if entry, ok := resolver.cache.Get("b"); !ok || !entry.Pending {
+		t.Fatalf("over-budget query should be queued, got %+v", entry)
+	}
+}
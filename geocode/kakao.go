@@ -0,0 +1,132 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultKakaoURL = "https://dapi.kakao.com"
+	ProviderKakao   = "kakao"
+	KakaoVersion    = "local-v2"
+)
+
+type Kakao struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	userAgent  string
+	throttle   throttle
+}
+
+type KakaoOption func(*Kakao)
+
+func WithKakaoBaseURL(baseURL string) KakaoOption {
+	return func(k *Kakao) {
+		if strings.TrimSpace(baseURL) != "" {
+			k.baseURL = baseURL
+		}
+	}
+}
+
+func WithKakaoHTTPClient(client *http.Client) KakaoOption {
+	return func(k *Kakao) {
+		if client != nil {
+			k.httpClient = client
+		}
+	}
+}
+
+func WithKakaoMinInterval(interval time.Duration) KakaoOption {
+	return func(k *Kakao) {
+		k.throttle.minInterval = interval
+	}
+}
+
+func NewKakao(apiKey string, opts ...KakaoOption) *Kakao {
+	k := &Kakao{
+		baseURL:    DefaultKakaoURL,
+		apiKey:     apiKey,
+		httpClient: http.DefaultClient,
+		userAgent:  "devatlas-geocoder/0.1",
+		throttle:   throttle{minInterval: 100 * time.Millisecond},
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k
+}
+
+func (k *Kakao) Geocode(ctx context.Context, query string) (Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return Result{Found: false}, nil
+	}
+	if k == nil {
+		return Result{}, errors.New("geocode: kakao is nil")
+	}
+	if strings.TrimSpace(k.apiKey) == "" {
+		return Result{}, errors.New("geocode: kakao api key is required")
+	}
+	if k.httpClient == nil {
+		k.httpClient = http.DefaultClient
+	}
+
+	if err := k.throttle.wait(ctx); err != nil {
+		return Result{}, err
+	}
+
+	endpoint := strings.TrimRight(k.baseURL, "/") + "/v2/local/search/address.json"
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("size", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "KakaoAK "+k.apiKey)
+	if strings.TrimSpace(k.userAgent) != "" {
+		req.Header.Set("User-Agent", k.userAgent)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("geocode: kakao status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Documents []struct {
+			X string `json:"x"`
+			Y string `json:"y"`
+		} `json:"documents"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Result{}, err
+	}
+	if len(payload.Documents) == 0 {
+		return Result{Found: false, Provider: ProviderKakao}, nil
+	}
+	lng, err := strconv.ParseFloat(payload.Documents[0].X, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	lat, err := strconv.ParseFloat(payload.Documents[0].Y, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Lat: lat, Lng: lng, Found: true, Provider: ProviderKakao}, nil
+}
@@ -9,19 +9,20 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
-const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+const (
+	DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+	ProviderNominatim   = "nominatim"
+	NominatimVersion    = "search-v1"
+)
 
 type Nominatim struct {
-	baseURL     string
-	httpClient  *http.Client
-	userAgent   string
-	minInterval time.Duration
-	mu          sync.Mutex
-	lastRequest time.Time
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	throttle   throttle
 }
 
 type NominatimOption func(*Nominatim)
@@ -50,16 +51,16 @@ func WithUserAgent(userAgent string) NominatimOption {
 
 func WithMinInterval(interval time.Duration) NominatimOption {
 	return func(n *Nominatim) {
-		n.minInterval = interval
+		n.throttle.minInterval = interval
 	}
 }
 
 func NewNominatim(opts ...NominatimOption) *Nominatim {
 	n := &Nominatim{
-		baseURL:     DefaultNominatimURL,
-		httpClient:  http.DefaultClient,
-		userAgent:   "devatlas-geocoder/0.1",
-		minInterval: 1 * time.Second,
+		baseURL:    DefaultNominatimURL,
+		httpClient: http.DefaultClient,
+		userAgent:  "devatlas-geocoder/0.1",
+		throttle:   throttle{minInterval: 1 * time.Second},
 	}
 	for _, opt := range opts {
 		opt(n)
@@ -78,7 +79,7 @@ func (n *Nominatim) Geocode(ctx context.Context, query string) (Result, error) {
 		n.httpClient = http.DefaultClient
 	}
 
-	if err := n.waitRateLimit(ctx); err != nil {
+	if err := n.throttle.wait(ctx); err != nil {
 		return Result{}, err
 	}
 
@@ -126,30 +127,5 @@ func (n *Nominatim) Geocode(ctx context.Context, query string) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
-	return Result{Lat: lat, Lng: lng, Found: true}, nil
-}
-
-func (n *Nominatim) waitRateLimit(ctx context.Context) error {
-	if n.minInterval <= 0 {
-		return nil
-	}
-	n.mu.Lock()
-	now := time.Now()
-	next := n.lastRequest.Add(n.minInterval)
-	if next.Before(now) || next.Equal(now) {
-		n.lastRequest = now
-		n.mu.Unlock()
-		return nil
-	}
-	n.lastRequest = next
-	n.mu.Unlock()
-	delay := time.Until(next)
-	timer := time.NewTimer(delay)
-	defer timer.Stop()
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-timer.C:
-		return nil
-	}
+	return Result{Lat: lat, Lng: lng, Found: true, Provider: ProviderNominatim}, nil
 }
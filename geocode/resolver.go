@@ -2,55 +2,272 @@ package geocode
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 )
 
 type Result struct {
-	Lat   float64
-	Lng   float64
-	Found bool
+	Lat        float64
+	Lng        float64
+	Found      bool
+	Provider   string
+	Sido       string
+	Confidence float64
+	Flag       string
 }
 
 type Geocoder interface {
 	Geocode(ctx context.Context, query string) (Result, error)
 }
 
+type acceptingGeocoder interface {
+	GeocodeAccepting(ctx context.Context, query string, accept func(Result) bool) (Result, error)
+}
+
 type Resolver struct {
-	geocoder Geocoder
-	cache    *Cache
-	now      func() time.Time
+	geocoder      Geocoder
+	cache         *Cache
+	validator     *Validator
+	minConfidence float64
+	positiveTTL   time.Duration
+	negativeTTL   time.Duration
+	guard         guard
+	now           func() time.Time
+}
+
+type ResolverOption func(*Resolver)
+
+func WithValidator(validator *Validator, minConfidence float64) ResolverOption {
+	return func(r *Resolver) {
+		r.validator = validator
+		r.minConfidence = minConfidence
+	}
+}
+
+func WithCacheTTL(positive, negative time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.positiveTTL = positive
+		r.negativeTTL = negative
+	}
+}
+
+// WithBudget caps the number of provider lookups this resolver makes; zero
+// leaves it unlimited.
+func WithBudget(lookups int) ResolverOption {
+	return func(r *Resolver) {
+		r.guard.budget = lookups
+	}
 }
 
-func NewResolver(geocoder Geocoder, cache *Cache) *Resolver {
-	return &Resolver{
-		geocoder: geocoder,
-		cache:    cache,
-		now:      time.Now,
+// WithCircuitBreaker stops provider lookups after threshold consecutive
+// failures and lets a single probe through once cooldown has passed.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.guard.threshold = threshold
+		r.guard.cooldown = cooldown
+	}
+}
+
+func NewResolver(geocoder Geocoder, cache *Cache, opts ...ResolverOption) *Resolver {
+	r := &Resolver{
+		geocoder:    geocoder,
+		cache:       cache,
+		positiveTTL: DefaultPositiveTTL,
+		negativeTTL: DefaultNegativeTTL,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
-func (r *Resolver) Resolve(ctx context.Context, query string) (Result, bool, error) {
+func (r *Resolver) Resolve(ctx context.Context, query, region string) (Result, bool, error) {
 	if r == nil || r.geocoder == nil {
 		return Result{Found: false}, false, nil
 	}
 	if strings.TrimSpace(query) == "" {
 		return Result{Found: false}, false, nil
 	}
-	if entry, ok := r.cache.Get(query); ok {
-		return Result{Lat: entry.Lat, Lng: entry.Lng, Found: entry.Found}, true, nil
+	entry, cached := r.cache.Get(query)
+	if cached && !r.Stale(entry) {
+		return r.cachedResult(entry, region), true, nil
 	}
-	result, err := r.geocoder.Geocode(ctx, query)
-	if err != nil {
+	result, err := r.guardedLookup(ctx, query, region)
+	if err == nil {
+		return result, false, nil
+	}
+	if ctx.Err() != nil {
 		return Result{}, false, err
 	}
+	if cached && entry.Found {
+		return r.cachedResult(entry, region), true, nil
+	}
+	r.queue(query, region)
+	return Result{Found: false}, false, err
+}
+
+func (r *Resolver) Refresh(ctx context.Context, entry CacheEntry) (Result, error) {
+	if r == nil || r.geocoder == nil {
+		return Result{Found: false}, nil
+	}
+	result, err := r.guardedLookup(ctx, entry.Query, entry.Region)
+	if err != nil && ctx.Err() == nil && !entry.Found {
+		r.queue(entry.Query, entry.Region)
+	}
+	return result, err
+}
+
+// ResolvePending retries queued queries until the budget or circuit breaker
+// stops it, and reports how many were resolved.
+func (r *Resolver) ResolvePending(ctx context.Context) (int, error) {
+	if r == nil || r.geocoder == nil {
+		return 0, nil
+	}
+	resolved := 0
+	for _, entry := range r.cache.List() {
+		if !entry.Pending {
+			continue
+		}
+		if _, err := r.guardedLookup(ctx, entry.Query, entry.Region); err != nil {
+			if ctx.Err() != nil {
+				return resolved, ctx.Err()
+			}
+			if errors.Is(err, ErrBudgetExhausted) || errors.Is(err, ErrCircuitOpen) {
+				break
+			}
+			continue
+		}
+		resolved++
+	}
+	return resolved, nil
+}
+
+func (r *Resolver) Stats() Stats {
+	if r == nil {
+		return Stats{}
+	}
+	stats := r.guard.snapshot()
+	for _, entry := range r.cache.List() {
+		if entry.Pending {
+			stats.Pending++
+		}
+	}
+	return stats
+}
+
+func (r *Resolver) cachedResult(entry CacheEntry, region string) Result {
+	result := Result{Lat: entry.Lat, Lng: entry.Lng, Found: entry.Found, Provider: entry.Provider}
+	if result.Found {
+		r.validate(&result, region)
+	} else {
+		result.Sido, result.Confidence, result.Flag = entry.Sido, entry.Confidence, entry.Flag
+	}
+	return result
+}
+
+func (r *Resolver) guardedLookup(ctx context.Context, query, region string) (Result, error) {
+	if err := r.guard.acquire(r.now()); err != nil {
+		return Result{Found: false}, err
+	}
+	result, err := r.lookup(ctx, query, region)
+	if ctx.Err() == nil {
+		r.guard.record(r.now(), err)
+	}
+	return result, err
+}
+
+func (r *Resolver) queue(query, region string) {
+	if entry, ok := r.cache.Get(query); ok && entry.Found {
+		return
+	}
+	r.cache.Set(query, CacheEntry{
+		Region:    region,
+		Pending:   true,
+		UpdatedAt: r.now(),
+	})
+}
+
+func (r *Resolver) Stale(entry CacheEntry) bool {
+	if r == nil {
+		return false
+	}
+	if entry.Expired(r.now(), r.positiveTTL, r.negativeTTL) {
+		return true
+	}
+	if entry.Pending {
+		return true
+	}
+	return entry.ProviderVersion != "" && entry.ProviderVersion != ProviderVersion(entry.Provider)
+}
+
+func (r *Resolver) lookup(ctx context.Context, query, region string) (Result, error) {
+	var rejected *Result
+	accept := func(candidate Result) bool {
+		if r.validate(&candidate, region) {
+			return true
+		}
+		if rejected == nil {
+			rejected = &candidate
+		}
+		return false
+	}
+
+	var result Result
+	var err error
+	if chain, ok := r.geocoder.(acceptingGeocoder); ok {
+		result, err = chain.GeocodeAccepting(ctx, query, accept)
+	} else {
+		result, err = r.geocoder.Geocode(ctx, query)
+		if err == nil && result.Found && !accept(result) {
+			result = Result{Found: false}
+		}
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if result.Found {
+		r.validate(&result, region)
+	} else if rejected != nil {
+		result = *rejected
+	} else if previous, ok := r.cache.Get(query); ok && previous.Found {
+		// No provider has it any more, which says less than the hit it had:
+		// keep the coordinates and only mark them as checked.
+		previous.UpdatedAt = r.now()
+		r.cache.Set(query, previous)
+		return r.cachedResult(previous, region), nil
+	}
+
 	if r.cache != nil {
 		r.cache.Set(query, CacheEntry{
-			Lat:       result.Lat,
-			Lng:       result.Lng,
-			Found:     result.Found,
-			UpdatedAt: r.now(),
+			Region:          region,
+			Lat:             result.Lat,
+			Lng:             result.Lng,
+			Found:           result.Found,
+			Provider:        result.Provider,
+			ProviderVersion: ProviderVersion(result.Provider),
+			Sido:            result.Sido,
+			Confidence:      result.Confidence,
+			Flag:            result.Flag,
+			UpdatedAt:       r.now(),
 		})
 	}
-	return result, false, nil
+	return result, nil
+}
+
+func (r *Resolver) validate(result *Result, region string) bool {
+	if r.validator == nil {
+		result.Confidence = 1
+		return true
+	}
+	check := r.validator.Check(result.Lat, result.Lng, region)
+	result.Sido = check.Sido
+	result.Confidence = check.Confidence
+	result.Flag = check.Flag
+	if check.Confidence < r.minConfidence {
+		result.Found = false
+		return false
+	}
+	return true
 }
@@ -0,0 +1,41 @@
+package geocode
+
+const defaultSigunguKm = 30.0
+
+type ReverseGeocoder struct {
+	boundaries *Boundaries
+	gazetteer  *Gazetteer
+	coastalKm  float64
+	sigunguKm  float64
+}
+
+func NewReverseGeocoder(boundaries *Boundaries, gazetteer *Gazetteer) *ReverseGeocoder {
+	return &ReverseGeocoder{
+		boundaries: boundaries,
+		gazetteer:  gazetteer,
+		coastalKm:  defaultCoastalKm,
+		sigunguKm:  defaultSigunguKm,
+	}
+}
+
+func (r *ReverseGeocoder) Reverse(lat, lng float64) (Place, bool) {
+	if r == nil || (lat == 0 && lng == 0) {
+		return Place{}, false
+	}
+	sido, inside := r.boundaries.Locate(lat, lng)
+	if !inside {
+		nearest, km, ok := r.gazetteer.Nearest(lat, lng, "")
+		if !ok || km > r.coastalKm {
+			return Place{}, false
+		}
+		sido = nearest.Sido
+	}
+	place, ok := r.gazetteer.Sido(sido)
+	if !ok {
+		place = Place{Sido: sido}
+	}
+	if sigungu, km, ok := r.gazetteer.Nearest(lat, lng, sido); ok && sigungu.Sigungu != "" && km <= r.sigunguKm {
+		place = sigungu
+	}
+	return place, true
+}
@@ -0,0 +1,38 @@
+package geocode
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type throttle struct {
+	minInterval time.Duration
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+func (t *throttle) wait(ctx context.Context) error {
+	if t.minInterval <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	now := time.Now()
+	next := t.lastRequest.Add(t.minInterval)
+	if next.Before(now) || next.Equal(now) {
+		t.lastRequest = now
+		t.mu.Unlock()
+		return nil
+	}
+	t.lastRequest = next
+	t.mu.Unlock()
+	delay := time.Until(next)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package geocode
+
+import (
+	"devatlas/mapper"
+)
+
+const (
+	FlagOutsideKorea   = "outside_korea"
+	FlagRegionMismatch = "region_mismatch"
+	FlagNearBoundary   = "near_boundary"
+	FlagUnverified     = "unverified"
+
+	DefaultMinConfidence = 0.5
+
+	defaultCoastalKm = 25.0
+)
+
+type Validation struct {
+	Sido       string
+	Confidence float64
+	Flag       string
+}
+
+type Validator struct {
+	boundaries *Boundaries
+	gazetteer  *Gazetteer
+	coastalKm  float64
+}
+
+func NewValidator(boundaries *Boundaries, gazetteer *Gazetteer) *Validator {
+	return &Validator{
+		boundaries: boundaries,
+		gazetteer:  gazetteer,
+		coastalKm:  defaultCoastalKm,
+	}
+}
+
+func (v *Validator) Check(lat, lng float64, region string) Validation {
+	if v == nil {
+		return Validation{Confidence: 1}
+	}
+	expected := mapper.NormalizeRegionName(region)
+
+	sido, inside := v.boundaries.Locate(lat, lng)
+	confidence := 1.0
+	flag := ""
+	if !inside {
+		place, km, ok := v.gazetteer.Nearest(lat, lng, "")
+		if !ok || km > v.coastalKm {
+			return Validation{Confidence: 0, Flag: FlagOutsideKorea}
+		}
+		sido = place.Sido
+		confidence = 0.6
+		flag = FlagNearBoundary
+	}
+
+	switch {
+	case expected == "":
+		if flag == "" {
+			flag = FlagUnverified
+		}
+		return Validation{Sido: sido, Confidence: min(confidence, 0.8), Flag: flag}
+	case expected != sido:
+		return Validation{Sido: sido, Confidence: 0.2, Flag: FlagRegionMismatch}
+	default:
+		return Validation{Sido: sido, Confidence: confidence, Flag: flag}
+	}
+}
@@ -0,0 +1,112 @@
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	t.Helper()
+	boundaries, err := NewBoundaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gazetteer, err := NewGazetteer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewValidator(boundaries, gazetteer)
+}
+
+func TestValidatorCheck(t *testing.T) {
+	validator := newTestValidator(t)
+	tests := []struct {
+		name     string
+		lat, lng float64
+		region   string
+		wantSido string
+		wantFlag string
+	}{
+		{"gwangju metro", 35.1595, 126.8526, "광주", "광주", ""},
+		{"gwangju-si in gyeonggi", 37.4295, 127.2550, "광주", "경기", FlagRegionMismatch},
+		{"no expected region", 35.1796, 129.0756, "", "부산", FlagUnverified},
+		{"tokyo", 35.6762, 139.6503, "서울", "", FlagOutsideKorea},
+	}
+	for _, tt := range tests {
+		got := validator.Check(tt.lat, tt.lng, tt.region)
+		if got.Sido != tt.wantSido || got.Flag != tt.wantFlag {
+			t.Errorf("%s: Check = %+v, want sido %q flag %q", tt.name, got, tt.wantSido, tt.wantFlag)
+		}
+	}
+}
+
+func TestResolverSkipsResultsOutsideExpectedRegion(t *testing.T) {
+	gyeonggi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"documents":[{"x":"127.2550","y":"37.4295"}]}`))
+	}))
+	defer gyeonggi.Close()
+	metro := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"documents":[{"x":"126.8526","y":"35.1595"}]}`))
+	}))
+	defer metro.Close()
+
+	chain := NewChain(
+		NewKakao("key", WithKakaoBaseURL(gyeonggi.URL), WithKakaoMinInterval(0)),
+		NewKakao("key", WithKakaoBaseURL(metro.URL), WithKakaoMinInterval(0)),
+	)
+	resolver := NewResolver(chain, &Cache{}, WithValidator(newTestValidator(t), DefaultMinConfidence))
+
+	result, _, err := resolver.Resolve(context.Background(), "광주", "광주")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Found || result.Sido != "광주" || result.Lat != 35.1595 {
+		t.Fatalf("Resolve = %+v, want the 광주광역시 hit", result)
+	}
+
+	single := NewResolver(NewKakao("key", WithKakaoBaseURL(gyeonggi.URL), WithKakaoMinInterval(0)), &Cache{},
+		WithValidator(newTestValidator(t), DefaultMinConfidence))
+	result, _, err = single.Resolve(context.Background(), "광주", "광주")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Found || result.Flag != FlagRegionMismatch {
+		t.Fatalf("Resolve = %+v, want rejected with %q", result, FlagRegionMismatch)
+	}
+}
+
+func TestReverseGeocoder(t *testing.T) {
+	boundaries, err := NewBoundaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gazetteer, err := NewGazetteer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverse := NewReverseGeocoder(boundaries, gazetteer)
+
+	tests := []struct {
+		name        string
+		lat, lng    float64
+		wantSido    string
+		wantSigungu string
+		wantOK      bool
+	}{
+		{"pangyo", 37.4020, 127.1086, "경기", "성남시 분당구", true},
+		{"daejeon", 36.3504, 127.3845, "대전", "", true},
+		{"tokyo", 35.6762, 139.6503, "", "", false},
+	}
+	for _, tt := range tests {
+		place, ok := reverse.Reverse(tt.lat, tt.lng)
+		if ok != tt.wantOK || place.Sido != tt.wantSido {
+			t.Errorf("%s: Reverse = %+v %v, want sido %q", tt.name, place, ok, tt.wantSido)
+			continue
+		}
+		if tt.wantSigungu != "" && place.Sigungu != tt.wantSigungu {
+			t.Errorf("%s: sigungu = %q, want %q", tt.name, place.Sigungu, tt.wantSigungu)
+		}
+	}
+}
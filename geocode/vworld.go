@@ -0,0 +1,176 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultVWorldURL = "https://api.vworld.kr"
+	ProviderVWorld   = "vworld"
+	VWorldVersion    = "address-2.0"
+)
+
+var defaultVWorldAddressTypes = []string{"ROAD", "PARCEL"}
+
+type VWorld struct {
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	userAgent    string
+	addressTypes []string
+	throttle     throttle
+}
+
+type VWorldOption func(*VWorld)
+
+func WithVWorldBaseURL(baseURL string) VWorldOption {
+	return func(v *VWorld) {
+		if strings.TrimSpace(baseURL) != "" {
+			v.baseURL = baseURL
+		}
+	}
+}
+
+func WithVWorldHTTPClient(client *http.Client) VWorldOption {
+	return func(v *VWorld) {
+		if client != nil {
+			v.httpClient = client
+		}
+	}
+}
+
+func WithVWorldMinInterval(interval time.Duration) VWorldOption {
+	return func(v *VWorld) {
+		v.throttle.minInterval = interval
+	}
+}
+
+func WithVWorldAddressTypes(types ...string) VWorldOption {
+	return func(v *VWorld) {
+		if len(types) > 0 {
+			v.addressTypes = append([]string(nil), types...)
+		}
+	}
+}
+
+func NewVWorld(apiKey string, opts ...VWorldOption) *VWorld {
+	v := &VWorld{
+		baseURL:      DefaultVWorldURL,
+		apiKey:       apiKey,
+		httpClient:   http.DefaultClient,
+		userAgent:    "devatlas-geocoder/0.1",
+		addressTypes: append([]string(nil), defaultVWorldAddressTypes...),
+		throttle:     throttle{minInterval: 100 * time.Millisecond},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+func (v *VWorld) Geocode(ctx context.Context, query string) (Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return Result{Found: false}, nil
+	}
+	if v == nil {
+		return Result{}, errors.New("geocode: vworld is nil")
+	}
+	if strings.TrimSpace(v.apiKey) == "" {
+		return Result{}, errors.New("geocode: vworld api key is required")
+	}
+	if v.httpClient == nil {
+		v.httpClient = http.DefaultClient
+	}
+	for _, addressType := range v.addressTypes {
+		result, err := v.getCoord(ctx, query, addressType)
+		if err != nil {
+			return Result{}, err
+		}
+		if result.Found {
+			return result, nil
+		}
+	}
+	return Result{Found: false, Provider: ProviderVWorld}, nil
+}
+
+func (v *VWorld) getCoord(ctx context.Context, query, addressType string) (Result, error) {
+	if err := v.throttle.wait(ctx); err != nil {
+		return Result{}, err
+	}
+
+	endpoint := strings.TrimRight(v.baseURL, "/") + "/req/address"
+	params := url.Values{}
+	params.Set("service", "address")
+	params.Set("request", "getcoord")
+	params.Set("version", "2.0")
+	params.Set("crs", "epsg:4326")
+	params.Set("format", "json")
+	params.Set("refine", "true")
+	params.Set("simple", "false")
+	params.Set("type", addressType)
+	params.Set("address", query)
+	params.Set("key", v.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	req.URL.RawQuery = params.Encode()
+	req.Header.Set("Accept", "application/json")
+	if strings.TrimSpace(v.userAgent) != "" {
+		req.Header.Set("User-Agent", v.userAgent)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("geocode: vworld status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Response struct {
+			Status string `json:"status"`
+			Error  struct {
+				Code string `json:"code"`
+				Text string `json:"text"`
+			} `json:"error"`
+			Result struct {
+				Point struct {
+					X string `json:"x"`
+					Y string `json:"y"`
+				} `json:"point"`
+			} `json:"result"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Result{}, err
+	}
+	switch payload.Response.Status {
+	case "OK":
+	case "NOT_FOUND":
+		return Result{Found: false, Provider: ProviderVWorld}, nil
+	default:
+		return Result{}, fmt.Errorf("geocode: vworld %s: %s", payload.Response.Error.Code, payload.Response.Error.Text)
+	}
+	lng, err := strconv.ParseFloat(payload.Response.Result.Point.X, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	lat, err := strconv.ParseFloat(payload.Response.Result.Point.Y, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Lat: lat, Lng: lng, Found: true, Provider: ProviderVWorld}, nil
+}
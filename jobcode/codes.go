@@ -0,0 +1,122 @@
+package jobcode
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	FamilyAppWeb     = "app_web"
+	FamilyDataAI     = "data_ai"
+	FamilyInfra      = "infra"
+	FamilySecurityQA = "security_qa"
+	FamilyGame       = "game"
+	FamilyOther      = "other"
+)
+
+type Entry struct {
+	Code   string
+	Name   string
+	Family string
+}
+
+var developerCodes = []Entry{
+	{Code: "84", Name: "백엔드/서버개발", Family: FamilyAppWeb},
+	{Code: "92", Name: "프론트엔드", Family: FamilyAppWeb},
+	{Code: "2232", Name: "풀스택", Family: FamilyAppWeb},
+	{Code: "86", Name: "앱개발", Family: FamilyAppWeb},
+	{Code: "195", Name: "Android", Family: FamilyAppWeb},
+	{Code: "234", Name: "iOS", Family: FamilyAppWeb},
+	{Code: "87", Name: "웹개발", Family: FamilyAppWeb},
+	{Code: "113", Name: "반응형웹", Family: FamilyAppWeb},
+	{Code: "124", Name: "웹표준·웹접근성", Family: FamilyAppWeb},
+	{Code: "2249", Name: "클라이언트", Family: FamilyAppWeb},
+	{Code: "103", Name: "검색엔진", Family: FamilyAppWeb},
+	{Code: "135", Name: "크롤링", Family: FamilyAppWeb},
+	{Code: "142", Name: "API", Family: FamilyAppWeb},
+	{Code: "82", Name: "데이터분석가", Family: FamilyDataAI},
+	{Code: "83", Name: "데이터엔지니어", Family: FamilyDataAI},
+	{Code: "2248", Name: "데이터 사이언티스트", Family: FamilyDataAI},
+	{Code: "2246", Name: "BI 엔지니어", Family: FamilyDataAI},
+	{Code: "106", Name: "데이터마이닝", Family: FamilyDataAI},
+	{Code: "107", Name: "데이터시각화", Family: FamilyDataAI},
+	{Code: "116", Name: "빅데이터", Family: FamilyDataAI},
+	{Code: "108", Name: "딥러닝", Family: FamilyDataAI},
+	{Code: "109", Name: "머신러닝", Family: FamilyDataAI},
+	{Code: "181", Name: "AI(인공지능)", Family: FamilyDataAI},
+	{Code: "160", Name: "NLP(자연어처리)", Family: FamilyDataAI},
+	{Code: "161", Name: "NLU(자연어이해)", Family: FamilyDataAI},
+	{Code: "133", Name: "컴퓨터비전", Family: FamilyDataAI},
+	{Code: "123", Name: "영상처리", Family: FamilyDataAI},
+	{Code: "162", Name: "OCR", Family: FamilyDataAI},
+	{Code: "171", Name: "STT", Family: FamilyDataAI},
+	{Code: "172", Name: "TTS", Family: FamilyDataAI},
+	{Code: "131", Name: "챗봇", Family: FamilyDataAI},
+	{Code: "148", Name: "DW", Family: FamilyDataAI},
+	{Code: "150", Name: "ETL", Family: FamilyDataAI},
+	{Code: "100", Name: "SE(시스템엔지니어)", Family: FamilyInfra},
+	{Code: "101", Name: "SI개발", Family: FamilyInfra},
+	{Code: "104", Name: "네트워크", Family: FamilyInfra},
+	{Code: "127", Name: "인프라", Family: FamilyInfra},
+	{Code: "136", Name: "클라우드", Family: FamilyInfra},
+	{Code: "146", Name: "DevOps", Family: FamilyInfra},
+	{Code: "156", Name: "IoT", Family: FamilyInfra},
+	{Code: "128", Name: "임베디드", Family: FamilyInfra},
+	{Code: "320", Name: "임베디드리눅스", Family: FamilyInfra},
+	{Code: "139", Name: "펌웨어", Family: FamilyInfra},
+	{Code: "180", Name: "아키텍쳐", Family: FamilyInfra},
+	{Code: "95", Name: "DBA", Family: FamilyInfra},
+	{Code: "145", Name: "DBMS", Family: FamilyInfra},
+	{Code: "164", Name: "RDBMS", Family: FamilyInfra},
+	{Code: "90", Name: "정보보안", Family: FamilySecurityQA},
+	{Code: "85", Name: "보안컨설팅", Family: FamilySecurityQA},
+	{Code: "2239", Name: "보안관제", Family: FamilySecurityQA},
+	{Code: "111", Name: "모의해킹", Family: FamilySecurityQA},
+	{Code: "132", Name: "취약점진단", Family: FamilySecurityQA},
+	{Code: "99", Name: "QA/테스터", Family: FamilySecurityQA},
+	{Code: "2229", Name: "SQA", Family: FamilySecurityQA},
+	{Code: "80", Name: "게임개발", Family: FamilyGame},
+}
+
+var byCode = func() map[string]Entry {
+	out := make(map[string]Entry, len(developerCodes))
+	for _, entry := range developerCodes {
+		out[entry.Code] = entry
+	}
+	return out
+}()
+
+func Lookup(code string) (Entry, bool) {
+	entry, ok := byCode[strings.TrimSpace(code)]
+	return entry, ok
+}
+
+func Family(code string) string {
+	if entry, ok := Lookup(code); ok {
+		return entry.Family
+	}
+	return ""
+}
+
+func Classify(codes []string) string {
+	for _, code := range codes {
+		if family := Family(code); family != "" {
+			return family
+		}
+	}
+	return ""
+}
+
+func Codes() []string {
+	out := make([]string, 0, len(developerCodes))
+	for _, entry := range developerCodes {
+		out = append(out, entry.Code)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, _ := strconv.Atoi(out[i])
+		b, _ := strconv.Atoi(out[j])
+		return a < b
+	})
+	return out
+}
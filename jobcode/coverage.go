@@ -0,0 +1,107 @@
+package jobcode
+
+import (
+	"sort"
+	"strings"
+)
+
+// Coverage tracks how many postings from a wide (job_mid_cd) query carry at
+// least one code from a job-code list, and which codes the rest used.
+type Coverage struct {
+	listed  map[string]bool
+	seen    map[string]bool
+	total   int
+	covered int
+	missed  map[string]*MissedCode
+}
+
+type MissedCode struct {
+	Code     string `json:"code"`
+	Name     string `json:"name,omitempty"`
+	Family   string `json:"family,omitempty"`
+	Postings int    `json:"postings"`
+}
+
+type CoverageReport struct {
+	Total       int          `json:"total"`
+	Covered     int          `json:"covered"`
+	Missed      int          `json:"missed"`
+	Share       float64      `json:"coverage_share"`
+	MissedCodes []MissedCode `json:"missed_codes"`
+}
+
+func NewCoverage(codes []string) *Coverage {
+	listed := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		listed[strings.TrimSpace(code)] = true
+	}
+	return &Coverage{
+		listed: listed,
+		seen:   map[string]bool{},
+		missed: map[string]*MissedCode{},
+	}
+}
+
+// Add records a posting by ID with its job codes and their names, in the
+// order the source lists them, and reports whether the list covers it.
+// Repeated IDs are classified again but counted once.
+func (c *Coverage) Add(id string, codes, names []string) bool {
+	covered := false
+	for _, code := range codes {
+		if c.listed[strings.TrimSpace(code)] {
+			covered = true
+			break
+		}
+	}
+	if id != "" {
+		if c.seen[id] {
+			return covered
+		}
+		c.seen[id] = true
+	}
+
+	c.total++
+	if covered {
+		c.covered++
+		return true
+	}
+	if len(codes) == 0 {
+		codes = []string{""}
+	}
+	for i, code := range codes {
+		code = strings.TrimSpace(code)
+		entry, ok := c.missed[code]
+		if !ok {
+			entry = &MissedCode{Code: code, Family: Family(code)}
+			c.missed[code] = entry
+		}
+		if entry.Name == "" && i < len(names) {
+			entry.Name = strings.TrimSpace(names[i])
+		}
+		entry.Postings++
+	}
+	return false
+}
+
+func (c *Coverage) Report() CoverageReport {
+	report := CoverageReport{
+		Total:       c.total,
+		Covered:     c.covered,
+		Missed:      c.total - c.covered,
+		MissedCodes: make([]MissedCode, 0, len(c.missed)),
+	}
+	if c.total > 0 {
+		report.Share = float64(c.covered) / float64(c.total)
+	}
+	for _, entry := range c.missed {
+		report.MissedCodes = append(report.MissedCodes, *entry)
+	}
+	sort.Slice(report.MissedCodes, func(i, j int) bool {
+		a, b := report.MissedCodes[i], report.MissedCodes[j]
+		if a.Postings == b.Postings {
+			return a.Code < b.Code
+		}
+		return a.Postings > b.Postings
+	})
+	return report
+}
@@ -0,0 +1,36 @@
+package jobcode
+
+import "testing"
+
+func TestCoverageReport(t *testing.T) {
+	coverage := NewCoverage([]string{"84", "92"})
+	postings := []struct {
+		id           string
+		codes, names []string
+		want         bool
+	}{
+		{"1", []string{"84"}, []string{"백엔드/서버개발"}, true},
+		{"2", []string{"2250", "92"}, []string{"기술지원", "프론트엔드"}, true},
+		{"3", []string{"2250"}, []string{"기술지원"}, false},
+		{"4", []string{"2250", "2251"}, []string{"기술지원", "IT컨설팅"}, false},
+		{"4", []string{"2250", "2251"}, []string{"기술지원", "IT컨설팅"}, false},
+		{"5", nil, nil, false},
+	}
+	for _, p := range postings {
+		if got := coverage.Add(p.id, p.codes, p.names); got != p.want {
+			t.Errorf("Add(%s) = %v, want %v", p.id, got, p.want)
+		}
+	}
+
+	report := coverage.Report()
+	if report.Total != 5 || report.Covered != 2 || report.Missed != 3 || report.Share != 0.4 {
+		t.Fatalf("report = %+v", report)
+	}
+	top := report.MissedCodes[0]
+	if top.Code != "2250" || top.Name != "기술지원" || top.Postings != 2 {
+		t.Fatalf("top missed = %+v", top)
+	}
+	if len(report.MissedCodes) != 3 {
+		t.Fatalf("missed codes = %+v, want 2250, 2251 and the uncoded bucket", report.MissedCodes)
+	}
+}
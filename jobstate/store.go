@@ -0,0 +1,242 @@
+package jobstate
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"devatlas/fsutil"
+	"devatlas/model"
+)
+
+const (
+	ReasonExpired     = "expired"
+	ReasonDisappeared = "disappeared"
+	ReasonClosed      = "closed"
+)
+
+type Store struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	// SweptAt is the last run whose window covered the whole current hiring
+	// period, so every open posting was either returned or gone.
+	SweptAt time.Time                     `json:"swept_at,omitempty"`
+	Jobs    map[string]model.PostingState `json:"jobs"`
+
+	listings map[string]string
+}
+
+func Load(path string) (*Store, error) {
+	if strings.TrimSpace(path) == "" {
+		return &Store{Jobs: map[string]model.PostingState{}}, nil
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Store{Jobs: map[string]model.PostingState{}}, nil
+		}
+		return nil, err
+	}
+	var store Store
+	if err := json.Unmarshal(payload, &store); err != nil {
+		return nil, err
+	}
+	if store.Jobs == nil {
+		store.Jobs = map[string]model.PostingState{}
+	}
+	return &store, nil
+}
+
+func Save(path string, store *Store) error {
+	if store == nil {
+		return nil
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	payload, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func (s *Store) Observe(job model.NormalizedJob) []model.FlowEvent {
+	if s == nil || job.SourceJobID == "" {
+		return nil
+	}
+	if s.Jobs == nil {
+		s.Jobs = map[string]model.PostingState{}
+	}
+	observedAt := job.ObservedAt
+	if observedAt.IsZero() {
+		observedAt = time.Now()
+	}
+
+	s.ensureListings()
+
+	var events []model.FlowEvent
+	entry, seen := s.Jobs[job.SourceJobID]
+	if !seen {
+		entry = model.PostingState{
+			JobID:     job.SourceJobID,
+			FirstSeen: observedAt,
+			RepostOf:  s.findRepost(job),
+		}
+		postedAt := job.PostedAt
+		if postedAt.IsZero() {
+			postedAt = observedAt
+		}
+		events = append(events, model.FlowEvent{Kind: model.FlowNew, Region: job.Region, At: postedAt})
+	} else if job.UpdatedAt.After(entry.UpdatedAt) {
+		events = append(events, model.FlowEvent{Kind: model.FlowUpdated, Region: job.Region, At: job.UpdatedAt})
+	}
+
+	entry.CompanyName = job.CompanyName
+	entry.Title = job.Title
+	if job.Region != "" {
+		entry.Region = job.Region
+		entry.RegionSource = job.RegionSource
+	}
+	if job.RoleFamily != "" {
+		entry.RoleFamily = job.RoleFamily
+	}
+	if !job.PostedAt.IsZero() {
+		entry.PostedAt = job.PostedAt
+	}
+	if job.UpdatedAt.After(entry.UpdatedAt) {
+		entry.UpdatedAt = job.UpdatedAt
+	}
+	if !job.ExpiresAt.IsZero() {
+		entry.ExpiresAt = job.ExpiresAt
+	}
+	if !observedAt.Before(entry.CountsAt) {
+		entry.ReadCount = job.ReadCount
+		entry.ApplyCount = job.ApplyCount
+		entry.CountsAt = observedAt
+	}
+	if observedAt.After(entry.LastSeen) {
+		entry.LastSeen = observedAt
+	}
+
+	if job.Active {
+		if entry.Closed() && entry.CloseReason != ReasonExpired {
+			entry.ClosedAt = time.Time{}
+			entry.CloseReason = ""
+		}
+	} else if !entry.Closed() {
+		closedAt := pickClosedAt(job.UpdatedAt, observedAt)
+		entry.ClosedAt = closedAt
+		entry.CloseReason = ReasonClosed
+		events = append(events, model.FlowEvent{Kind: model.FlowExpired, Region: entry.Region, At: closedAt})
+	}
+
+	s.Jobs[job.SourceJobID] = entry
+	if key := listingKey(entry.CompanyName, entry.Title); key != "" {
+		s.listings[key] = entry.JobID
+	}
+	return events
+}
+
+// Sweep closes postings after a run at now that queried postings updated
+// since windowStart. Postings past their expiry are closed as expired. An
+// open posting the run did not return is closed as disappeared only when its
+// last known update falls inside the window: an unchanged posting that still
+// existed would have been returned, while one last updated before the window
+// was simply not asked for.
+func (s *Store) Sweep(now, windowStart time.Time) []model.FlowEvent {
+	if s == nil {
+		return nil
+	}
+	var events []model.FlowEvent
+	for id, entry := range s.Jobs {
+		if entry.Closed() {
+			continue
+		}
+		switch {
+		case !entry.ExpiresAt.IsZero() && !entry.ExpiresAt.After(now):
+			entry.ClosedAt = entry.ExpiresAt
+			entry.CloseReason = ReasonExpired
+			events = append(events, model.FlowEvent{Kind: model.FlowExpired, Region: entry.Region, At: entry.ExpiresAt})
+		case entry.LastSeen.Before(now) && covered(entry, windowStart):
+			entry.ClosedAt = now
+			entry.CloseReason = ReasonDisappeared
+			events = append(events, model.FlowEvent{Kind: model.FlowDisappeared, Region: entry.Region, At: now})
+		default:
+			continue
+		}
+		s.Jobs[id] = entry
+	}
+	s.UpdatedAt = now
+	return events
+}
+
+func covered(entry model.PostingState, windowStart time.Time) bool {
+	updated := entry.UpdatedAt
+	if updated.IsZero() {
+		updated = entry.PostedAt
+	}
+	return !updated.IsZero() && !windowStart.IsZero() && !updated.Before(windowStart)
+}
+
+func (s *Store) Prune(cutoff time.Time) int {
+	if s == nil {
+		return 0
+	}
+	removed := 0
+	for id, entry := range s.Jobs {
+		if entry.Closed() && entry.ClosedAt.Before(cutoff) {
+			delete(s.Jobs, id)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.listings = nil
+	}
+	return removed
+}
+
+func (s *Store) ensureListings() {
+	if s.listings != nil {
+		return
+	}
+	s.listings = map[string]string{}
+	for id, entry := range s.Jobs {
+		key := listingKey(entry.CompanyName, entry.Title)
+		if key == "" {
+			continue
+		}
+		if current, ok := s.listings[key]; ok && s.Jobs[current].FirstSeen.After(entry.FirstSeen) {
+			continue
+		}
+		s.listings[key] = id
+	}
+}
+
+func (s *Store) findRepost(job model.NormalizedJob) string {
+	key := listingKey(job.CompanyName, job.Title)
+	if key == "" {
+		return ""
+	}
+	previous, ok := s.listings[key]
+	if !ok || previous == job.SourceJobID {
+		return ""
+	}
+	return previous
+}
+
+func listingKey(company, title string) string {
+	company = strings.Join(strings.Fields(strings.ToLower(company)), " ")
+	title = strings.Join(strings.Fields(strings.ToLower(title)), " ")
+	if company == "" || title == "" {
+		return ""
+	}
+	return company + "|" + title
+}
+
+func pickClosedAt(updatedAt, observedAt time.Time) time.Time {
+	if !updatedAt.IsZero() && !updatedAt.After(observedAt) {
+		return updatedAt
+	}
+	return observedAt
+}
@@ -0,0 +1,168 @@
+package jobstate
+
+import (
+	"testing"
+	"time"
+
+	"devatlas/model"
+)
+
+var day = time.Date(2026, 3, 2, 0, 10, 0, 0, time.UTC)
+
+func TestObserve(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     *model.PostingState
+		job        model.NormalizedJob
+		wantKinds  []model.FlowKind
+		wantClosed string
+	}{
+		{
+			name:      "new posting",
+			job:       model.NormalizedJob{SourceJobID: "1", Region: "서울", Active: true, PostedAt: day.Add(-time.Hour), ObservedAt: day},
+			wantKinds: []model.FlowKind{model.FlowNew},
+		},
+		{
+			name:      "updated posting",
+			before:    &model.PostingState{JobID: "1", UpdatedAt: day.Add(-48 * time.Hour), FirstSeen: day.Add(-48 * time.Hour)},
+			job:       model.NormalizedJob{SourceJobID: "1", Active: true, UpdatedAt: day.Add(-time.Hour), ObservedAt: day},
+			wantKinds: []model.FlowKind{model.FlowUpdated},
+		},
+		{
+			name:   "unchanged posting",
+			before: &model.PostingState{JobID: "1", UpdatedAt: day.Add(-time.Hour), FirstSeen: day.Add(-48 * time.Hour)},
+			job:    model.NormalizedJob{SourceJobID: "1", Active: true, UpdatedAt: day.Add(-time.Hour), ObservedAt: day},
+		},
+		{
+			name:       "closed by the employer",
+			before:     &model.PostingState{JobID: "1", FirstSeen: day.Add(-48 * time.Hour)},
+			job:        model.NormalizedJob{SourceJobID: "1", Active: false, ObservedAt: day},
+			wantKinds:  []model.FlowKind{model.FlowExpired},
+			wantClosed: ReasonClosed,
+		},
+		{
+			name:   "reappeared after a disappearance",
+			before: &model.PostingState{JobID: "1", FirstSeen: day.Add(-48 * time.Hour), ClosedAt: day.Add(-24 * time.Hour), CloseReason: ReasonDisappeared},
+			job:    model.NormalizedJob{SourceJobID: "1", Active: true, ObservedAt: day},
+		},
+		{
+			name:       "expired stays closed",
+			before:     &model.PostingState{JobID: "1", FirstSeen: day.Add(-48 * time.Hour), ClosedAt: day.Add(-24 * time.Hour), CloseReason: ReasonExpired},
+			job:        model.NormalizedJob{SourceJobID: "1", Active: true, ObservedAt: day},
+			wantClosed: ReasonExpired,
+		},
+	}
+	for _, tt := range tests {
+		store := &Store{Jobs: map[string]model.PostingState{}}
+		if tt.before != nil {
+			store.Jobs[tt.before.JobID] = *tt.before
+		}
+		events := store.Observe(tt.job)
+		if len(events) != len(tt.wantKinds) {
+			t.Errorf("%s: events = %+v, want %v", tt.name, events, tt.wantKinds)
+			continue
+		}
+		for i, event := range events {
+			if event.Kind != tt.wantKinds[i] {
+				t.Errorf("%s: event %d = %s, want %s", tt.name, i, event.Kind, tt.wantKinds[i])
+			}
+		}
+		entry := store.Jobs[tt.job.SourceJobID]
+		if entry.CloseReason != tt.wantClosed || !entry.LastSeen.Equal(day) {
+			t.Errorf("%s: entry = %+v, want close reason %q and last seen %s", tt.name, entry, tt.wantClosed, day)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := day
+	windowStart := now.Add(-24 * time.Hour)
+	tests := []struct {
+		name       string
+		entry      model.PostingState
+		wantReason string
+		wantKind   model.FlowKind
+	}{
+		{
+			name:       "past its expiry",
+			entry:      model.PostingState{LastSeen: now, UpdatedAt: now.Add(-30 * 24 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+			wantReason: ReasonExpired,
+			wantKind:   model.FlowExpired,
+		},
+		{
+			name:       "updated inside the window but not returned",
+			entry:      model.PostingState{LastSeen: now.Add(-24 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour)},
+			wantReason: ReasonDisappeared,
+			wantKind:   model.FlowDisappeared,
+		},
+		{
+			name:  "not updated since before the window",
+			entry: model.PostingState{LastSeen: now.Add(-30 * 24 * time.Hour), UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+		},
+		{
+			name:  "returned by this run",
+			entry: model.PostingState{LastSeen: now, UpdatedAt: now.Add(-2 * time.Hour)},
+		},
+		{
+			name:  "no update time",
+			entry: model.PostingState{LastSeen: now.Add(-30 * 24 * time.Hour)},
+		},
+		{
+			name:  "already closed",
+			entry: model.PostingState{LastSeen: now.Add(-24 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour), ClosedAt: now.Add(-time.Hour), CloseReason: ReasonClosed},
+		},
+	}
+	for _, tt := range tests {
+		tt.entry.JobID = "1"
+		wasClosed := tt.entry.Closed()
+		store := &Store{Jobs: map[string]model.PostingState{"1": tt.entry}}
+		events := store.Sweep(now, windowStart)
+		entry := store.Jobs["1"]
+		if tt.wantReason == "" {
+			if len(events) != 0 || (!wasClosed && entry.Closed()) {
+				t.Errorf("%s: events = %+v entry = %+v, want the posting left alone", tt.name, events, entry)
+			}
+			continue
+		}
+		if len(events) != 1 || events[0].Kind != tt.wantKind || entry.CloseReason != tt.wantReason {
+			t.Errorf("%s: events = %+v entry = %+v, want %s/%s", tt.name, events, entry, tt.wantKind, tt.wantReason)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	cutoff := day.AddDate(0, 0, -180)
+	store := &Store{Jobs: map[string]model.PostingState{
+		"old-closed":    {JobID: "old-closed", ClosedAt: cutoff.Add(-time.Hour)},
+		"recent-closed": {JobID: "recent-closed", ClosedAt: cutoff.Add(time.Hour)},
+		"old-open":      {JobID: "old-open", LastSeen: cutoff.Add(-time.Hour)},
+	}}
+	if removed := store.Prune(cutoff); removed != 1 {
+		t.Fatalf("Prune removed %d, want 1", removed)
+	}
+	if _, ok := store.Jobs["old-closed"]; ok {
+		t.Fatal("Prune kept a posting closed before the cutoff")
+	}
+}
+
+func TestObserveLinksReposts(t *testing.T) {
+	store := &Store{Jobs: map[string]model.PostingState{}}
+	store.Observe(model.NormalizedJob{SourceJobID: "1", CompanyName: "Acme", Title: "Backend Engineer", Active: true, ObservedAt: day})
+	store.Observe(model.NormalizedJob{SourceJobID: "2", CompanyName: " acme ", Title: "backend  engineer", Active: true, ObservedAt: day.Add(time.Hour)})
+	store.Observe(model.NormalizedJob{SourceJobID: "3", CompanyName: "Acme", Title: "Frontend Engineer", Active: true, ObservedAt: day.Add(time.Hour)})
+
+	if got := store.Jobs["2"].RepostOf; got != "1" {
+		t.Fatalf("repost_of = %q, want 1", got)
+	}
+	if got := store.Jobs["3"].RepostOf; got != "" {
+		t.Fatalf("different title repost_of = %q, want none", got)
+	}
+
+	// The listing index is rebuilt from saved state, so a reload still links
+	// the next repost to the latest posting.
+	reloaded := &Store{Jobs: store.Jobs}
+	reloaded.Observe(model.NormalizedJob{SourceJobID: "4", CompanyName: "Acme", Title: "Backend Engineer", Active: true, ObservedAt: day.Add(2 * time.Hour)})
+	if got := reloaded.Jobs["4"].RepostOf; got != "2" {
+		t.Fatalf("repost_of after reload = %q, want 2", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Package jsonschema checks JSON documents against the small subset of JSON
+// Schema (draft 2020-12) that the published outputs need: types, required
+// properties, map values, array items and lengths, numeric bounds, enums and
+// date-time strings.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// Types is the "type" keyword, written as a string when it holds one type.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("jsonschema: type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// Parse decodes a schema document.
+func Parse(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return &schema, nil
+}
+
+// Validate decodes data and checks it against s. Each problem is reported
+// with the JSON pointer of the offending value.
+func (s *Schema) Validate(data []byte) []string {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	s.check("", doc, &problems)
+	return problems
+}
+
+func (s *Schema) check(pointer string, value any, problems *[]string) {
+	if s == nil {
+		return
+	}
+	report := func(format string, args ...any) {
+		at := pointer
+		if at == "" {
+			at = "/"
+		}
+		*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.Type) > 0 && !s.Type.match(value) {
+		report("want %s, got %s", strings.Join(s.Type, " or "), typeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, value) {
+		report("%v is not one of %v", value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := pointer + "/" + escape(name)
+			if property, ok := s.Properties[name]; ok {
+				property.check(child, v[name], problems)
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				s.AdditionalProperties.check(child, v[name], problems)
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("has %d items, want at least %d", len(v), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("has %d items, want at most %d", len(v), *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.check(pointer+"/"+strconv.Itoa(i), item, problems)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			report("%v is below the minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			report("%v is above the maximum %v", v, *s.Maximum)
+		}
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				report("%q is not a date-time", v)
+			}
+		}
+	}
+}
+
+func (t Types) match(value any) bool {
+	for _, name := range t {
+		switch name {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func contains(values []any, value any) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
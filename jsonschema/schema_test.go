@@ -0,0 +1,67 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["meta", "regions"],
+	"properties": {
+		"meta": {
+			"type": "object",
+			"required": ["run_at"],
+			"properties": {"run_at": {"type": "string", "format": "date-time"}}
+		},
+		"regions": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["region", "job_count"],
+				"properties": {
+					"region": {"type": "string", "enum": ["서울", "부산"]},
+					"job_count": {"type": "integer", "minimum": 0},
+					"role_families": {"type": ["object", "null"], "additionalProperties": {"type": "integer"}}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `{"meta":{"run_at":"2026-01-10T09:00:00+09:00"},"regions":[{"region":"서울","job_count":3,"role_families":{"app_web":2}}]}`, nil},
+		{"null map", `{"meta":{"run_at":"2026-01-10T09:00:00Z"},"regions":[{"region":"부산","job_count":0,"role_families":null}]}`, nil},
+		{"empty regions", `{"meta":{"run_at":"2026-01-10T09:00:00Z"},"regions":[]}`, []string{"/regions: has 0 items"}},
+		{"missing meta", `{"regions":[{"region":"서울","job_count":1}]}`, []string{`/: missing required property "meta"`}},
+		{"bad values", `{"meta":{"run_at":"yesterday"},"regions":[{"region":"도쿄","job_count":1.5,"role_families":{"x":"y"}}]}`, []string{
+			`/meta/run_at: "yesterday" is not a date-time`,
+			"/regions/0/job_count: want integer, got number",
+			"/regions/0/region: 도쿄 is not one of",
+			"/regions/0/role_families/x: want integer, got string",
+		}},
+		{"negative", `{"meta":{"run_at":"2026-01-10T09:00:00Z"},"regions":[{"region":"서울","job_count":-1}]}`, []string{"/regions/0/job_count: -1 is below the minimum 0"}},
+	}
+	for _, tt := range tests {
+		got := schema.Validate([]byte(tt.doc))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Validate = %q, want %d problems", tt.name, got, len(tt.want))
+			continue
+		}
+		for i := range got {
+			if !strings.HasPrefix(got[i], tt.want[i]) {
+				t.Errorf("%s: problem %d = %q, want prefix %q", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
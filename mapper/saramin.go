@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"devatlas/jobcode"
 	"devatlas/model"
 	"devatlas/saramin"
 )
@@ -16,6 +17,12 @@ func NormalizeSaraminJob(job saramin.Job, observedAt time.Time) model.Normalized
 
 	locationCodes := splitCSV(job.Position.Location.Code)
 	locationNames := splitCSV(job.Position.Location.Name)
+	jobCodes := splitCSV(job.Position.JobCode.Code)
+	region := extractRegion(locationNames)
+	regionSource := ""
+	if region != "" {
+		regionSource = model.RegionSourceLocation
+	}
 
 	return model.NormalizedJob{
 		Source:        "saramin",
@@ -26,12 +33,17 @@ func NormalizeSaraminJob(job saramin.Job, observedAt time.Time) model.Normalized
 		Title:         job.Position.Title,
 		JobMidCode:    job.Position.JobMidCode.Code,
 		JobCode:       job.Position.JobCode.Code,
+		JobCodes:      jobCodes,
+		RoleFamily:    classifyRoleFamily(jobCodes),
 		JobTypeCode:   job.Position.JobType.Code,
 		LocationCodes: locationCodes,
 		LocationNames: locationNames,
-		Region:        extractRegion(locationNames),
+		Region:        region,
+		RegionSource:  regionSource,
 		Keywords:      splitCSV(job.Keyword),
 		Active:        parseActive(job.Active),
+		ReadCount:     parseCount(job.ReadCnt),
+		ApplyCount:    parseCount(job.ApplyCnt),
 		PostedAt:      parseUnix(job.PostingTimestamp),
 		UpdatedAt:     parseUnix(job.ModificationTimestamp),
 		ExpiresAt:     parseUnix(job.ExpirationTimestamp),
@@ -39,6 +51,16 @@ func NormalizeSaraminJob(job saramin.Job, observedAt time.Time) model.Normalized
 	}
 }
 
+func classifyRoleFamily(jobCodes []string) string {
+	if len(jobCodes) == 0 {
+		return ""
+	}
+	if family := jobcode.Classify(jobCodes); family != "" {
+		return family
+	}
+	return jobcode.FamilyOther
+}
+
 func splitCSV(value string) []string {
 	if strings.TrimSpace(value) == "" {
 		return nil
@@ -95,7 +117,7 @@ func extractRegionFromName(name string) string {
 		if parts := strings.Fields(candidate); len(parts) > 0 {
 			candidate = parts[0]
 		}
-		if region := normalizeRegionName(candidate); region != "" {
+		if region := NormalizeRegionName(candidate); region != "" {
 			return region
 		}
 	}
@@ -154,7 +176,7 @@ var regionAliases = []regionAlias{
 	{"제주", "제주"},
 }
 
-func normalizeRegionName(value string) string {
+func NormalizeRegionName(value string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
 		return ""
@@ -188,6 +210,18 @@ func parseUnix(value saramin.StringOrNumber) time.Time {
 	return time.Unix(seconds, 0)
 }
 
+func parseCount(value saramin.StringOrNumber) int {
+	raw := strings.ReplaceAll(strings.TrimSpace(string(value)), ",", "")
+	if raw == "" {
+		return 0
+	}
+	count, err := strconv.Atoi(raw)
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
+
 func parseActive(value saramin.StringOrNumber) bool {
 	raw := strings.TrimSpace(string(value))
 	return raw == "1"
@@ -2,6 +2,15 @@ package model
 
 import "time"
 
+const (
+	PrecisionAddress = "address"
+	PrecisionSigungu = "sigungu"
+	PrecisionSido    = "sido"
+
+	RegionSourceLocation = "location"
+	RegionSourceReverse  = "reverse_geocode"
+)
+
 type NormalizedJob struct {
 	Source        string
 	SourceJobID   string
@@ -11,14 +20,21 @@ type NormalizedJob struct {
 	Title         string
 	JobMidCode    string
 	JobCode       string
+	JobCodes      []string
+	RoleFamily    string
 	JobTypeCode   string
 	LocationCodes []string
 	LocationNames []string
 	Region        string
+	RegionSource  string
+	Address       string
 	Keywords      []string
 	Active        bool
+	ReadCount     int
+	ApplyCount    int
 	Latitude      float64
 	Longitude     float64
+	GeoPrecision  string
 	PostedAt      time.Time
 	UpdatedAt     time.Time
 	ExpiresAt     time.Time
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type PostingState struct {
+	JobID        string    `json:"job_id"`
+	CompanyName  string    `json:"company,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	RegionSource string    `json:"region_source,omitempty"`
+	RoleFamily   string    `json:"role_family,omitempty"`
+	PostedAt     time.Time `json:"posted_at,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	ClosedAt     time.Time `json:"closed_at,omitempty"`
+	CloseReason  string    `json:"close_reason,omitempty"`
+	RepostOf     string    `json:"repost_of,omitempty"`
+	ReadCount    int       `json:"read_count,omitempty"`
+	ApplyCount   int       `json:"apply_count,omitempty"`
+	CountsAt     time.Time `json:"counts_at,omitempty"`
+}
+
+func (s PostingState) Closed() bool {
+	return !s.ClosedAt.IsZero()
+}
+
+type FlowKind string
+
+const (
+	FlowNew         FlowKind = "new"
+	FlowUpdated     FlowKind = "updated"
+	FlowExpired     FlowKind = "expired"
+	FlowDisappeared FlowKind = "disappeared"
+)
+
+type FlowEvent struct {
+	Kind   FlowKind
+	Region string
+	At     time.Time
+}
@@ -0,0 +1,151 @@
+// Package output defines the JSON documents the static site reads. Every
+// document carries SchemaVersion, and a change that removes a field or
+// changes its type has to bump it; see the schemas directory.
+package output
+
+import (
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/timeseries"
+)
+
+// SchemaVersion is the version of every published document.
+const SchemaVersion = 1
+
+type RegionCountsMeta struct {
+	SchemaVersion  int       `json:"schema_version"`
+	RunAt          time.Time `json:"run_at"`
+	WindowStart    time.Time `json:"window_start"`
+	WindowEnd      time.Time `json:"window_end"`
+	MissingRegions int       `json:"missing_regions" schema:"minimum=0"`
+	DerivedRegions int       `json:"derived_regions" schema:"minimum=0"`
+	StatsSource    string    `json:"stats_source,omitempty"`
+}
+
+// RegionCounts is region_counts.json.
+type RegionCounts struct {
+	Meta    RegionCountsMeta        `json:"meta"`
+	Regions []aggregate.RegionCount `json:"regions" schema:"minItems=1"`
+}
+
+type LatestCompaniesMeta struct {
+	SchemaVersion int       `json:"schema_version"`
+	RunAt         time.Time `json:"run_at"`
+	RegionLevel   string    `json:"region_level"`
+}
+
+type Company struct {
+	Name         string         `json:"name"`
+	Lat          float64        `json:"lat" schema:"minimum=-90,maximum=90"`
+	Lng          float64        `json:"lng" schema:"minimum=-180,maximum=180"`
+	Region       string         `json:"region"`
+	Address      string         `json:"address,omitempty"`
+	Precision    string         `json:"precision" schema:"enum=address|sigungu|sido"`
+	Approximate  bool           `json:"approximate"`
+	URL          string         `json:"url"`
+	AsOf         string         `json:"asof"`
+	RoleFamilies map[string]int `json:"role_families,omitempty"`
+}
+
+// LatestCompanies is latest_companies.json.
+type LatestCompanies struct {
+	Meta      LatestCompaniesMeta `json:"meta"`
+	Companies []Company           `json:"companies"`
+}
+
+// CompaniesGeoJSON is latest_companies.geojson, a FeatureCollection of the
+// same companies.
+type CompaniesGeoJSON struct {
+	Type     string              `json:"type" schema:"enum=FeatureCollection"`
+	Meta     LatestCompaniesMeta `json:"meta"`
+	Features []Feature           `json:"features"`
+}
+
+type Feature struct {
+	Type       string  `json:"type" schema:"enum=Feature"`
+	Geometry   Point   `json:"geometry"`
+	Properties Company `json:"properties"`
+}
+
+type Point struct {
+	Type        string     `json:"type" schema:"enum=Point"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type ClusterTileMeta struct {
+	SchemaVersion int       `json:"schema_version"`
+	RunAt         time.Time `json:"run_at"`
+	Zoom          int       `json:"zoom" schema:"minimum=0"`
+	CellPx        int       `json:"cell_px" schema:"minimum=1"`
+	Companies     int       `json:"companies" schema:"minimum=0"`
+}
+
+// ClusterTile is clusters/z{zoom}.json.
+type ClusterTile struct {
+	Meta     ClusterTileMeta     `json:"meta"`
+	Clusters []aggregate.Cluster `json:"clusters"`
+}
+
+type PostingLifetimesMeta struct {
+	SchemaVersion int       `json:"schema_version"`
+	RunAt         time.Time `json:"run_at"`
+	Postings      int       `json:"postings" schema:"minimum=0"`
+}
+
+// PostingLifetimes is posting_lifetimes.json.
+type PostingLifetimes struct {
+	Meta         PostingLifetimesMeta      `json:"meta"`
+	Regions      []aggregate.LifetimeStats `json:"regions"`
+	RoleFamilies []aggregate.LifetimeStats `json:"role_families"`
+}
+
+type EngagementMeta struct {
+	SchemaVersion int       `json:"schema_version"`
+	RunAt         time.Time `json:"run_at"`
+	Cutoff        time.Time `json:"cutoff"`
+	Postings      int       `json:"postings" schema:"minimum=0"`
+}
+
+// Engagement is engagement.json.
+type Engagement struct {
+	Meta         EngagementMeta              `json:"meta"`
+	Regions      []aggregate.EngagementStats `json:"regions"`
+	RoleFamilies []aggregate.EngagementStats `json:"role_families"`
+}
+
+// Manifest is manifest.json, written last so the site can check that every
+// file it loads belongs to the same run.
+type Manifest struct {
+	SchemaVersion int            `json:"schema_version"`
+	RunID         string         `json:"run_id"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Generator     string         `json:"generator"`
+	Files         []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Path    string `json:"path"`
+	Bytes   int64  `json:"bytes" schema:"minimum=0"`
+	SHA256  string `json:"sha256"`
+	Records int    `json:"records" schema:"minimum=0"`
+}
+
+// Document names a published file and the type it decodes into.
+type Document struct {
+	Name  string
+	Value any
+}
+
+// Documents lists every published document; region_timeseries.json is the
+// time-series store itself.
+var Documents = []Document{
+	{"region_counts", RegionCounts{}},
+	{"latest_companies", LatestCompanies{}},
+	{"latest_companies.geojson", CompaniesGeoJSON{}},
+	{"cluster_tile", ClusterTile{}},
+	{"posting_lifetimes", PostingLifetimes{}},
+	{"engagement", Engagement{}},
+	{"region_timeseries", timeseries.Series{}},
+	{"manifest", Manifest{}},
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"devatlas/jsonschema"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Schema generates the JSON Schema of a document from its Go type. Fields
+// without omitempty are required, nil slices and maps may be null, as may
+// pointers without omitempty, and a `schema` struct tag adds bounds: minimum,
+// maximum, minItems, maxItems and enum (values separated by |). Every
+// schema_version field must equal SchemaVersion.
+func Schema(doc Document) *jsonschema.Schema {
+	schema := schemaOf(reflect.TypeOf(doc.Value))
+	schema.Schema = jsonschema.Draft
+	schema.ID = doc.Name + ".schema.json"
+	schema.Title = doc.Name
+	return schema
+}
+
+// Lookup returns the document with the given name.
+func Lookup(name string) (Document, bool) {
+	for _, doc := range Documents {
+		if doc.Name == name {
+			return doc, true
+		}
+	}
+	return Document{}, false
+}
+
+func schemaOf(t reflect.Type) *jsonschema.Schema {
+	switch {
+	case t == timeType:
+		return &jsonschema.Schema{Type: jsonschema.Types{"string"}, Format: "date-time"}
+	case t == rawMessageType:
+		return &jsonschema.Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaOf(t.Elem())
+		schema.Type = append(schema.Type, "null")
+		return schema
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: jsonschema.Types{"boolean"}}
+	case reflect.String:
+		return &jsonschema.Schema{Type: jsonschema.Types{"string"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonschema.Schema{Type: jsonschema.Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &jsonschema.Schema{Type: jsonschema.Types{"integer"}, Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &jsonschema.Schema{Type: jsonschema.Types{"number"}}
+	case reflect.Slice:
+		return &jsonschema.Schema{Type: jsonschema.Types{"array", "null"}, Items: schemaOf(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &jsonschema.Schema{Type: jsonschema.Types{"array"}, Items: schemaOf(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &jsonschema.Schema{Type: jsonschema.Types{"object", "null"}, AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		schema := &jsonschema.Schema{Type: jsonschema.Types{"object"}, Properties: map[string]*jsonschema.Schema{}}
+		addFields(schema, t)
+		return schema
+	default:
+		panic(fmt.Sprintf("output: no schema for %s", t))
+	}
+}
+
+func addFields(schema *jsonschema.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := schemaOf(field.Type)
+		if field.Type.Kind() == reflect.Pointer && strings.Contains(opts, "omitempty") {
+			// A nil pointer is left out rather than written as null.
+			property = schemaOf(field.Type.Elem())
+		}
+		if name == "schema_version" {
+			property.Enum = []any{float64(SchemaVersion)}
+		}
+		applyTag(property, field.Tag.Get("schema"))
+		schema.Properties[name] = property
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func applyTag(schema *jsonschema.Schema, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "minimum", "maximum":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				panic(fmt.Sprintf("output: bad schema tag %q", tag))
+			}
+			if key == "minimum" {
+				schema.Minimum = &n
+			} else {
+				schema.Maximum = &n
+			}
+		case "minItems", "maxItems":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("output: bad schema tag %q", tag))
+			}
+			if key == "minItems" {
+				schema.MinItems = &n
+				// A required minimum also rules out null.
+				schema.Type = jsonschema.Types{"array"}
+			} else {
+				schema.MaxItems = &n
+			}
+		case "enum":
+			for _, option := range strings.Split(value, "|") {
+				schema.Enum = append(schema.Enum, option)
+			}
+		default:
+			panic(fmt.Sprintf("output: unknown schema tag %q", tag))
+		}
+	}
+}
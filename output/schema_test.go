@@ -0,0 +1,124 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"devatlas/jsonschema"
+)
+
+var update = flag.Bool("update", false, "rewrite the committed schemas in schemas/")
+
+// TestSchemasCompatible compares the generated schemas with the committed
+// ones. While SchemaVersion is unchanged a removed field or a changed type
+// fails; any other difference only asks for the files to be regenerated.
+func TestSchemasCompatible(t *testing.T) {
+	for _, doc := range Documents {
+		path := filepath.Join("schemas", doc.Name+".schema.json")
+		generated, err := json.MarshalIndent(Schema(doc), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		generated = append(generated, '\n')
+		if *update {
+			if err := os.WriteFile(path, generated, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		committed, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%v (run go test ./output -update)", err)
+		}
+		golden, err := jsonschema.Parse(committed)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if schemaVersion(golden) == SchemaVersion {
+			for _, change := range breakingChanges("", golden, Schema(doc)) {
+				t.Errorf("%s: %s without bumping SchemaVersion", doc.Name, change)
+			}
+		}
+		if !bytes.Equal(committed, generated) {
+			t.Errorf("%s is out of date; run go test ./output -update", path)
+		}
+	}
+}
+
+func TestBreakingChangesDetected(t *testing.T) {
+	doc, _ := Lookup("latest_companies")
+	old := Schema(doc)
+	changed := Schema(doc)
+	company := changed.Properties["companies"].Items
+	delete(company.Properties, "asof")
+	company.Properties["lat"] = &jsonschema.Schema{Type: jsonschema.Types{"string"}}
+	company.Properties["city"] = &jsonschema.Schema{Type: jsonschema.Types{"string"}}
+
+	got := breakingChanges("", old, changed)
+	want := []string{"/companies/items/asof removed", "/companies/items/lat changed from number to string"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("breakingChanges = %q, want %q", got, want)
+	}
+}
+
+func TestSchemaAcceptsDocuments(t *testing.T) {
+	doc, _ := Lookup("region_counts")
+	schema := Schema(doc)
+	valid := `{"meta":{"schema_version":1,"run_at":"2026-01-10T09:00:00Z","window_start":"2026-01-09T09:00:00Z","window_end":"2026-01-10T09:00:00Z","missing_regions":0,"derived_regions":0},
+		"regions":[{"region":"서울","job_count":1,"company_count":1,"company_hhi":1,"top5_company_share":1,"companies_5plus":0}]}`
+	if problems := schema.Validate([]byte(valid)); len(problems) > 0 {
+		t.Fatalf("problems = %q", problems)
+	}
+	stale := strings.Replace(valid, `"schema_version":1`, `"schema_version":0`, 1)
+	if problems := schema.Validate([]byte(stale)); len(problems) != 1 {
+		t.Fatalf("problems for an old version = %q", problems)
+	}
+}
+
+func schemaVersion(schema *jsonschema.Schema) int {
+	version := schema.Properties["schema_version"]
+	if meta := schema.Properties["meta"]; meta != nil {
+		version = meta.Properties["schema_version"]
+	}
+	if version == nil || len(version.Enum) != 1 {
+		return 0
+	}
+	n, _ := version.Enum[0].(float64)
+	return int(n)
+}
+
+func breakingChanges(pointer string, old, current *jsonschema.Schema) []string {
+	if old == nil {
+		return nil
+	}
+	if current == nil {
+		return []string{pointer + " removed"}
+	}
+	var changes []string
+	if types(old) != types(current) {
+		changes = append(changes, pointer+" changed from "+types(old)+" to "+types(current))
+	}
+	names := make([]string, 0, len(old.Properties))
+	for name := range old.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		changes = append(changes, breakingChanges(pointer+"/"+name, old.Properties[name], current.Properties[name])...)
+	}
+	changes = append(changes, breakingChanges(pointer+"/items", old.Items, current.Items)...)
+	return append(changes, breakingChanges(pointer+"/values", old.AdditionalProperties, current.AdditionalProperties)...)
+}
+
+func types(schema *jsonschema.Schema) string {
+	list := append([]string(nil), schema.Type...)
+	sort.Strings(list)
+	return strings.Join(list, "|")
+}
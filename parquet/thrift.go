@@ -0,0 +1,89 @@
+package parquet
+
+import "bytes"
+
+// Thrift compact protocol type IDs.
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes the footer and page headers with the Thrift compact
+// protocol. Fields must be written in increasing ID order within a struct.
+type compactWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16
+}
+
+func (w *compactWriter) field(id int16, typ byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(zigzag(int64(id)))
+	}
+	w.lastID = id
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, compactI32)
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, compactI64)
+	w.varint(zigzag(v))
+}
+
+func (w *compactWriter) string(id int16, v string) {
+	w.field(id, compactBinary)
+	w.bytes(v)
+}
+
+func (w *compactWriter) bytes(v string) {
+	w.varint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+// list writes a list header; the caller then writes n elements.
+func (w *compactWriter) list(id int16, elem byte, n int) {
+	w.field(id, compactList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elem)
+	w.varint(uint64(n))
+}
+
+// begin opens a struct, either as field id or, with id zero, as a list
+// element or the top-level value.
+func (w *compactWriter) begin(id int16) {
+	if id != 0 {
+		w.field(id, compactStruct)
+	}
+	w.stack = append(w.stack, w.lastID)
+	w.lastID = 0
+}
+
+func (w *compactWriter) end() {
+	w.buf.WriteByte(0)
+	w.lastID = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+func (w *compactWriter) varint(v uint64) {
+	for v >= 0x80 {
+		w.buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	w.buf.WriteByte(byte(v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
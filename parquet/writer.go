@@ -0,0 +1,312 @@
+// Package parquet writes flat tables as Apache Parquet files: one row group,
+// one uncompressed PLAIN-encoded data page per column, and every column
+// optional. That is enough for the analyst exports, which are small and are
+// read with pandas, DuckDB or Arrow.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const magic = "PAR1"
+
+type Type int
+
+const (
+	String Type = iota
+	Int64
+	Float64
+	Bool
+	// Timestamp columns hold UTC milliseconds; the zero time is written as null.
+	Timestamp
+)
+
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Physical, converted and encoding IDs from parquet.thrift.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageData = 0
+)
+
+// Writer buffers rows in memory and writes the file on Close.
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	values  [][]any
+	rows    int
+	closed  bool
+
+	// CreatedBy is recorded in the file footer.
+	CreatedBy string
+}
+
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:         w,
+		columns:   columns,
+		values:    make([][]any, len(columns)),
+		CreatedBy: "devatlas",
+	}
+}
+
+// Write appends a row with one value per column. A nil value is null;
+// otherwise the value must match the column type: string, int or int64,
+// float64, bool, or time.Time.
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values, want %d", len(row), len(w.columns))
+	}
+	for i, value := range row {
+		normalized, err := normalize(w.columns[i], value)
+		if err != nil {
+			return err
+		}
+		w.values[i] = append(w.values[i], normalized)
+	}
+	w.rows++
+	return nil
+}
+
+func normalize(column Column, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	ok := false
+	switch column.Type {
+	case String:
+		_, ok = value.(string)
+	case Int64:
+		switch v := value.(type) {
+		case int:
+			value, ok = int64(v), true
+		case int64:
+			ok = true
+		}
+	case Float64:
+		_, ok = value.(float64)
+	case Bool:
+		_, ok = value.(bool)
+	case Timestamp:
+		var t time.Time
+		if t, ok = value.(time.Time); ok {
+			if t.IsZero() {
+				return nil, nil
+			}
+			value = t.UnixMilli()
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("parquet: column %s: unexpected value %T", column.Name, value)
+	}
+	return value, nil
+}
+
+// Close writes the column chunks and the footer. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+	chunks := make([]chunkMeta, len(w.columns))
+	if w.rows > 0 {
+		for i, column := range w.columns {
+			page := encodePage(column, w.values[i])
+			offset := int64(file.Len())
+			file.Write(page)
+			chunks[i] = chunkMeta{offset: offset, size: int64(len(page))}
+		}
+	}
+	footer := w.footer(chunks)
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(magic)
+	_, err := w.w.Write(file.Bytes())
+	return err
+}
+
+type chunkMeta struct {
+	offset int64
+	size   int64
+}
+
+// encodePage returns a page header followed by a data page holding the
+// definition levels and the non-null values of one column.
+func encodePage(column Column, values []any) []byte {
+	var data bytes.Buffer
+	levels := definitionLevels(values)
+	binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+	data.Write(levels)
+
+	var bits []bool
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			binary.Write(&data, binary.LittleEndian, uint32(len(v)))
+			data.WriteString(v)
+		case int64:
+			binary.Write(&data, binary.LittleEndian, v)
+		case float64:
+			binary.Write(&data, binary.LittleEndian, math.Float64bits(v))
+		case bool:
+			bits = append(bits, v)
+		}
+	}
+	if column.Type == Bool {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		data.Write(packed)
+	}
+
+	var header compactWriter
+	header.begin(0)
+	header.i32(1, pageData)
+	header.i32(2, int32(data.Len()))
+	header.i32(3, int32(data.Len()))
+	header.begin(5)
+	header.i32(1, int32(len(values)))
+	header.i32(2, encodingPlain)
+	header.i32(3, encodingRLE)
+	header.i32(4, encodingRLE)
+	header.end()
+	header.end()
+	return append(header.buf.Bytes(), data.Bytes()...)
+}
+
+// definitionLevels encodes 1 for a value and 0 for a null as runs of the
+// RLE/bit-packed hybrid encoding with a bit width of one.
+func definitionLevels(values []any) []byte {
+	var out compactWriter
+	for i := 0; i < len(values); {
+		level := values[i] != nil
+		run := 1
+		for i+run < len(values) && (values[i+run] != nil) == level {
+			run++
+		}
+		out.varint(uint64(run) << 1)
+		if level {
+			out.buf.WriteByte(1)
+		} else {
+			out.buf.WriteByte(0)
+		}
+		i += run
+	}
+	return out.buf.Bytes()
+}
+
+func (w *Writer) footer(chunks []chunkMeta) []byte {
+	var meta compactWriter
+	meta.begin(0)
+	meta.i32(1, 1)
+
+	meta.list(2, compactStruct, len(w.columns)+1)
+	meta.begin(0)
+	meta.string(4, "schema")
+	meta.i32(5, int32(len(w.columns)))
+	meta.end()
+	for _, column := range w.columns {
+		physical, converted := column.Type.physical()
+		meta.begin(0)
+		meta.i32(1, physical)
+		meta.i32(3, repetitionOptional)
+		meta.string(4, column.Name)
+		if converted >= 0 {
+			meta.i32(6, converted)
+		}
+		meta.end()
+	}
+
+	meta.i64(3, int64(w.rows))
+
+	groups := 0
+	if w.rows > 0 {
+		groups = 1
+	}
+	meta.list(4, compactStruct, groups)
+	if groups > 0 {
+		var total int64
+		for _, chunk := range chunks {
+			total += chunk.size
+		}
+		meta.begin(0)
+		meta.list(1, compactStruct, len(w.columns))
+		for i, column := range w.columns {
+			physical, _ := column.Type.physical()
+			meta.begin(0)
+			meta.i64(2, chunks[i].offset)
+			meta.begin(3)
+			meta.i32(1, physical)
+			meta.list(2, compactI32, 2)
+			meta.varint(zigzag(encodingPlain))
+			meta.varint(zigzag(encodingRLE))
+			meta.list(3, compactBinary, 1)
+			meta.bytes(column.Name)
+			meta.i32(4, 0)
+			meta.i64(5, int64(w.rows))
+			meta.i64(6, chunks[i].size)
+			meta.i64(7, chunks[i].size)
+			meta.i64(9, chunks[i].offset)
+			meta.end()
+			meta.end()
+		}
+		meta.i64(2, total)
+		meta.i64(3, int64(w.rows))
+		meta.end()
+	}
+
+	if w.CreatedBy != "" {
+		meta.string(6, w.CreatedBy)
+	}
+	meta.end()
+	return meta.buf.Bytes()
+}
+
+func (t Type) physical() (physical, converted int32) {
+	switch t {
+	case String:
+		return physicalByteArray, convertedUTF8
+	case Int64:
+		return physicalInt64, -1
+	case Float64:
+		return physicalDouble, -1
+	case Bool:
+		return physicalBoolean, -1
+	case Timestamp:
+		return physicalInt64, convertedTimestampMillis
+	default:
+		return physicalByteArray, -1
+	}
+}
@@ -0,0 +1,178 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// compactReader decodes compact-protocol structs into maps keyed by field
+// ID, which is all the test needs to walk the footer and page headers.
+type compactReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *compactReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *compactReader) varint() uint64 {
+	var v uint64
+	for shift := 0; ; shift += 7 {
+		b := r.byte()
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+}
+
+func (r *compactReader) int() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case compactI32, compactI64:
+		return r.int()
+	case compactBinary:
+		n := int(r.varint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case compactList:
+		header := r.byte()
+		n, elem := int(header>>4), header&0x0f
+		if n == 15 {
+			n = int(r.varint())
+		}
+		out := make([]any, n)
+		for i := range out {
+			out[i] = r.value(elem)
+		}
+		return out
+	case compactStruct:
+		return r.structure()
+	}
+	panic("unexpected compact type")
+}
+
+func (r *compactReader) structure() map[int]any {
+	out := map[int]any{}
+	last := 0
+	for {
+		header := r.byte()
+		if header == 0 {
+			return out
+		}
+		id := last + int(header>>4)
+		if header>>4 == 0 {
+			id = int(r.int())
+		}
+		out[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	columns := []Column{
+		{Name: "job_id", Type: String},
+		{Name: "read_count", Type: Int64},
+		{Name: "lat", Type: Float64},
+		{Name: "active", Type: Bool},
+		{Name: "posted_at", Type: Timestamp},
+	}
+	posted := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns)
+	rows := [][]any{
+		{"a", 3, 37.5, true, posted},
+		{nil, int64(7), nil, false, time.Time{}},
+		{"c", nil, 35.1, true, posted},
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write([]any{1, 2, 3, 4, 5}); err == nil {
+		t.Fatal("Write accepted an int for a string column")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := buf.Bytes()
+	if string(file[:4]) != magic || string(file[len(file)-4:]) != magic {
+		t.Fatalf("file does not start and end with %q", magic)
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := &compactReader{data: file[len(file)-8-footerLen : len(file)-8]}
+	meta := footer.structure()
+	if meta[3].(int64) != 3 {
+		t.Fatalf("num_rows = %v, want 3", meta[3])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(columns)+1 || schema[1].(map[int]any)[4] != "job_id" {
+		t.Fatalf("schema = %v", schema)
+	}
+	chunks := meta[4].([]any)[0].(map[int]any)[1].([]any)
+
+	page := func(i int) (map[int]any, *compactReader) {
+		offset := chunks[i].(map[int]any)[3].(map[int]any)[9].(int64)
+		r := &compactReader{data: file, pos: int(offset)}
+		header := r.structure()
+		return header, r
+	}
+	// Skip the definition levels: a length prefix and the RLE runs.
+	values := func(r *compactReader) *compactReader {
+		n := int(binary.LittleEndian.Uint32(file[r.pos:]))
+		r.pos += 4 + n
+		return r
+	}
+
+	header, r := page(0)
+	if header[5].(map[int]any)[1].(int64) != 3 {
+		t.Fatalf("job_id page header = %v, want 3 values", header)
+	}
+	levels := file[r.pos+4 : r.pos+4+int(binary.LittleEndian.Uint32(file[r.pos:]))]
+	if !bytes.Equal(levels, []byte{2, 1, 2, 0, 2, 1}) {
+		t.Fatalf("job_id definition levels = %v", levels)
+	}
+	values(r)
+	for _, want := range []string{"a", "c"} {
+		n := int(binary.LittleEndian.Uint32(file[r.pos:]))
+		if got := string(file[r.pos+4 : r.pos+4+n]); got != want {
+			t.Fatalf("job_id value = %q, want %q", got, want)
+		}
+		r.pos += 4 + n
+	}
+
+	_, r = page(2)
+	values(r)
+	if got := math.Float64frombits(binary.LittleEndian.Uint64(file[r.pos:])); got != 37.5 {
+		t.Fatalf("lat = %v, want 37.5", got)
+	}
+
+	_, r = page(3)
+	values(r)
+	if file[r.pos] != 0b101 {
+		t.Fatalf("active bits = %b, want 101", file[r.pos])
+	}
+
+	_, r = page(4)
+	values(r)
+	if got := int64(binary.LittleEndian.Uint64(file[r.pos:])); got != posted.UnixMilli() {
+		t.Fatalf("posted_at = %d, want %d", got, posted.UnixMilli())
+	}
+}
@@ -0,0 +1,89 @@
+package rawstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"devatlas/model"
+)
+
+const maxLineSize = 4 * 1024 * 1024
+
+// ReadRange calls fn for each job archived in dir on days from through to,
+// oldest file first. A zero bound leaves that side open.
+func ReadRange(dir string, from, to time.Time, fn func(model.RawJob) error) error {
+	files, err := Files(dir, from, to)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := readFile(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files lists the daily archive files in dir between from and to.
+func Files(dir string, from, to time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lower, upper string
+	if !from.IsZero() {
+		lower = from.Format("20060102")
+	}
+	if !to.IsZero() {
+		upper = to.Format("20060102")
+	}
+	var out []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "raw-") || !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		dateKey := strings.TrimSuffix(strings.TrimPrefix(name, "raw-"), ".jsonl")
+		if (lower != "" && dateKey < lower) || (upper != "" && dateKey > upper) {
+			continue
+		}
+		out = append(out, filepath.Join(dir, name))
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func readFile(path string, fn func(model.RawJob) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var job model.RawJob
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil {
+			return fmt.Errorf("rawstore: %s:%d: %w", filepath.Base(path), line, err)
+		}
+		if err := fn(job); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
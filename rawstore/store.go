@@ -2,12 +2,14 @@ package rawstore
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"devatlas/fsutil"
 	"devatlas/model"
 )
 
@@ -52,6 +54,14 @@ func (s *FileStore) Append(job model.RawJob) error {
 	return nil
 }
 
+// Flush writes buffered postings to the current file.
+func (s *FileStore) Flush() error {
+	if s == nil || s.writer == nil {
+		return nil
+	}
+	return s.writer.Flush()
+}
+
 func (s *FileStore) Close() error {
 	if s == nil {
 		return nil
@@ -90,8 +100,7 @@ func (s *FileStore) rotate(dateKey string) error {
 		return err
 	}
 
-	name := fmt.Sprintf("raw-%s.jsonl", dateKey)
-	path := filepath.Join(s.dir, name)
+	path := filepath.Join(s.dir, fileName(dateKey))
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
 		return err
@@ -101,3 +110,25 @@ func (s *FileStore) rotate(dateKey string) error {
 	s.currentDate = dateKey
 	return nil
 }
+
+// WriteDay replaces the archive file for day with jobs, so writing the same
+// day again never duplicates postings.
+func WriteDay(dir string, day time.Time, jobs []model.RawJob) error {
+	if dir == "" {
+		return fmt.Errorf("rawstore: directory is required")
+	}
+	var buf bytes.Buffer
+	for _, job := range jobs {
+		payload, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		buf.Write(payload)
+		buf.WriteByte('\n')
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(dir, fileName(day.Format("20060102"))), buf.Bytes(), 0o644)
+}
+
+func fileName(dateKey string) string {
+	return fmt.Sprintf("raw-%s.jsonl", dateKey)
+}
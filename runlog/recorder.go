@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -54,7 +55,7 @@ func (r *Recorder) Start(runAt, windowStart, windowEnd time.Time) (*RunRecord, e
 		runAt = r.now()
 	}
 	record := &RunRecord{
-		ID:          runAt.Format("20060102T150405Z0700"),
+		ID:          FormatID(runAt),
 		RunAt:       runAt,
 		WindowStart: windowStart,
 		WindowEnd:   windowEnd,
@@ -67,6 +68,11 @@ func (r *Recorder) Start(runAt, windowStart, windowEnd time.Time) (*RunRecord, e
 	return record, nil
 }
 
+// FormatID returns the record ID for a run started at runAt.
+func FormatID(runAt time.Time) string {
+	return runAt.Format("20060102T150405Z0700")
+}
+
 func (r *Recorder) Finish(record *RunRecord, runErr error) error {
 	if r == nil {
 		return errors.New("runlog: recorder is nil")
@@ -85,6 +91,45 @@ func (r *Recorder) Finish(record *RunRecord, runErr error) error {
 	return r.write(record)
 }
 
+// Resume marks an earlier record as started again, keeping its ID and window.
+func (r *Recorder) Resume(record *RunRecord) error {
+	if r == nil {
+		return errors.New("runlog: recorder is nil")
+	}
+	if record == nil {
+		return errors.New("runlog: record is nil")
+	}
+	record.StartedAt = r.now()
+	record.CompletedAt = time.Time{}
+	record.Status = StatusStarted
+	record.Error = ""
+	return r.write(record)
+}
+
+// Latest returns the most recent record, or nil when none has been written.
+func (r *Recorder) Latest() (*RunRecord, error) {
+	if r == nil {
+		return nil, errors.New("runlog: recorder is nil")
+	}
+	paths, err := filepath.Glob(filepath.Join(r.dir, "run-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	sort.Strings(paths)
+	payload, err := os.ReadFile(paths[len(paths)-1])
+	if err != nil {
+		return nil, err
+	}
+	var record RunRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, fmt.Errorf("runlog: %s: %w", paths[len(paths)-1], err)
+	}
+	return &record, nil
+}
+
 func (r *Recorder) write(record *RunRecord) error {
 	if err := os.MkdirAll(r.dir, 0o755); err != nil {
 		return err
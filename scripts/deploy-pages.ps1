@@ -17,6 +17,7 @@ if (-not (Test-Path -Path $DataDir)) {
     Write-Error "Data directory not found: $DataDir"
     exit 1
 }
+$DataPath = (Resolve-Path -Path $DataDir).Path
 
 if (-not (Test-Path -Path $WorkDir)) {
     git clone $RepoUrl $WorkDir | Out-Null
@@ -43,7 +44,12 @@ try {
         Remove-Item -Recurse -Force "data"
     }
     New-Item -ItemType Directory -Force -Path "data" | Out-Null
-    Copy-Item -Recurse -Force (Join-Path $PSScriptRoot ".." $DataDir "*") "data"
+    $PagesData = Join-Path (Get-Location) "data"
+    Push-Location (Join-Path $PSScriptRoot "..")
+    go run ./cmd/devatlas -data-dir $DataPath publish -out $PagesData
+    $publishExit = $LASTEXITCODE
+    Pop-Location
+    if ($publishExit -ne 0) { exit $publishExit }
 
     git add -A | Out-Null
     $status = git status --porcelain
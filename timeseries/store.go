@@ -0,0 +1,141 @@
+package timeseries
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"devatlas/aggregate"
+	"devatlas/fsutil"
+)
+
+type Meta struct {
+	SchemaVersion int       `json:"schema_version"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type RegionPoint struct {
+	Date         string `json:"date"`
+	Region       string `json:"region"`
+	JobCount     int    `json:"job_count"`
+	CompanyCount int    `json:"company_count"`
+	Source       string `json:"source,omitempty"`
+}
+
+// SourceBackfill marks points counted from postings published that day
+// rather than from a daily collection run.
+const SourceBackfill = "backfill"
+
+type Series struct {
+	Meta    Meta                  `json:"meta"`
+	Regions []RegionPoint         `json:"regions"`
+	Flow    []aggregate.FlowCount `json:"flow"`
+}
+
+func Load(path string) (*Series, error) {
+	if strings.TrimSpace(path) == "" {
+		return &Series{}, nil
+	}
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Series{}, nil
+		}
+		return nil, err
+	}
+	var series Series
+	if err := json.Unmarshal(payload, &series); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func Save(path string, series *Series) error {
+	if series == nil {
+		return nil
+	}
+	if series.Regions == nil {
+		series.Regions = []RegionPoint{}
+	}
+	if series.Flow == nil {
+		series.Flow = []aggregate.FlowCount{}
+	}
+	payload, err := json.Marshal(series)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(payload, '\n'), 0o644)
+}
+
+func (s *Series) UpsertRegions(date string, counts []aggregate.RegionCount) {
+	s.UpsertSourceRegions(date, "", counts)
+}
+
+// UpsertSourceRegions replaces every point for date with counts tagged with
+// source; an empty source means a daily collection run.
+func (s *Series) UpsertSourceRegions(date, source string, counts []aggregate.RegionCount) {
+	if s == nil || date == "" {
+		return
+	}
+	kept := s.Regions[:0]
+	for _, point := range s.Regions {
+		if point.Date != date {
+			kept = append(kept, point)
+		}
+	}
+	s.Regions = kept
+	for _, count := range counts {
+		s.Regions = append(s.Regions, RegionPoint{
+			Date:         date,
+			Region:       count.Region,
+			JobCount:     count.JobCount,
+			CompanyCount: count.CompanyCount,
+			Source:       source,
+		})
+	}
+	sort.SliceStable(s.Regions, func(i, j int) bool {
+		if s.Regions[i].Date == s.Regions[j].Date {
+			return s.Regions[i].Region < s.Regions[j].Region
+		}
+		return s.Regions[i].Date < s.Regions[j].Date
+	})
+}
+
+// SourceOf reports the source of the points stored for date and whether
+// there are any.
+func (s *Series) SourceOf(date string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	for _, point := range s.Regions {
+		if point.Date == date {
+			return point.Source, true
+		}
+	}
+	return "", false
+}
+
+func (s *Series) AddFlow(counts []aggregate.FlowCount) {
+	if s == nil || len(counts) == 0 {
+		return
+	}
+	index := make(map[string]int, len(s.Flow))
+	for i, count := range s.Flow {
+		index[count.Date+"|"+count.Region] = i
+	}
+	for _, count := range counts {
+		key := count.Date + "|" + count.Region
+		if i, ok := index[key]; ok {
+			s.Flow[i].New += count.New
+			s.Flow[i].Updated += count.Updated
+			s.Flow[i].Expired += count.Expired
+			s.Flow[i].Disappeared += count.Disappeared
+			continue
+		}
+		index[key] = len(s.Flow)
+		s.Flow = append(s.Flow, count)
+	}
+	aggregate.SortFlowCounts(s.Flow)
+}